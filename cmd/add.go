@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addCmd.PersistentFlags().String("from", "", "Insert a backdated interval starting at this moment, like 09:00")
+	addCmd.PersistentFlags().String("to", "", "The end of the backdated interval, like 10:30 or now")
+	addCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the adjustment")
+	rootCmd.AddCommand(addCmd)
+}
+
+var addCmd = &cobra.Command{
+	Use:	"add [name] [duration]",
+	Short:	"Add time to a timer",
+	Long:	"Add a duration like 1h30m to a named timer, or insert a backdated interval with --from and --to",
+	Args:	cobra.MaximumNArgs(2),
+	Run:	addMain,
+}
+
+// adjustmentArgs splits the arguments of the manual adjustment commands into a timer name and a duration.
+func adjustmentArgs(cmd *cobra.Command, args []string) (string, time.Duration) {
+	if len(args) == 0 {
+		Die("Give the duration to %s, like 1h30m", cmd.Name())
+	}
+
+	d, err := time.ParseDuration(args[len(args) - 1])
+	MaybeDie(err)
+	if d < 0 {
+		Die("The duration must not be negative")
+	}
+
+	return timerName(args[:len(args) - 1]), d
+}
+
+func addMain(cmd *cobra.Command, args []string){
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	from := parseMomentFlag(cmd, "from")
+	to := parseMomentFlag(cmd, "to")
+	backdated := !from.IsZero() || !to.IsZero()
+	if backdated && (from.IsZero() || to.IsZero()) {
+		Die("The --from and --to flags must be given together")
+	}
+	if backdated && len(args) > 1 {
+		Die("A backdated interval can't be combined with a duration")
+	}
+
+	var name string
+	var d time.Duration
+	if backdated {
+		name = timerName(args)
+	} else {
+		name, d = adjustmentArgs(cmd, args)
+	}
+
+	unlock := lockTimer(name)
+	defer unlock()
+
+	t, err := store.Load(name)
+	MaybeDie(err)
+
+	if backdated {
+		slog.Debug("Inserting interval", "Name", name, "From", from, "To", to)
+		err = t.AddInterval(from, to, note)
+	} else {
+		slog.Debug("Adding time", "Name", name, "Duration", d)
+		err = t.Adjust(d, note)
+	}
+	MaybeDie(err)
+
+	err = store.Dump(name, t)
+	MaybeDie(err)
+
+	slog.Debug("Time added", "Name", name, "Timer", t)
+	fmt.Println(t.ElapsedString())
+}
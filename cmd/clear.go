@@ -9,6 +9,8 @@ import (
 
 func init() {
 	clearCmd.PersistentFlags().BoolP("all", "A", false, "Remove all timers")
+	clearCmd.PersistentFlags().BoolP("recursive", "r", false, "Also remove every timer below the named one")
+	addTagFilterFlags(clearCmd)
 	rootCmd.AddCommand(clearCmd)
 }
 
@@ -17,29 +19,91 @@ var clearCmd = &cobra.Command{
 	Short:	"Clear timers",
 	Long:	"Clear timers",
 	Args:	cobra.MaximumNArgs(1),
+	ValidArgsFunction:	completeTimerNames(anyNode),
 	Run:	clearMain,
 }
 
+// clearTimer removes a timer under its lock, and forgets it in the state so that resume can't pick it. When
+// match is given, the timer was picked from an earlier scan, so it is loaded again under the lock and only
+// cleared if it still exists and still matches.
+func clearTimer(name string, match func(t *timer.Timer) bool) {
+	unlock := lockTimer(name)
+	defer unlock()
+
+	if match != nil {
+		exists, err := store.Exists(name)
+		MaybeDie(err)
+		if !exists {
+			slog.Debug("Timer no longer exists", "Name", name)
+			return
+		}
+
+		t, err := store.Load(name, true)
+		MaybeDie(err)
+		if !match(t) {
+			slog.Debug("Timer no longer matches", "Name", name)
+			return
+		}
+	}
+
+	err := store.Clear(name)
+	MaybeDie(err)
+
+	err = store.UpdateState(func(state *timer.State) {
+		state.Forget(name)
+	})
+	MaybeDie(err)
+}
+
 func clearMain(cmd *cobra.Command, args []string){
 	all, err := cmd.Flags().GetBool("all")
 	MaybeDie(err)
 
-	cacheDir := timer.GetCacheDir()
+	recursive, err := cmd.Flags().GetBool("recursive")
+	MaybeDie(err)
+
+	include, exclude := tagFilters(cmd)
+	filtered := len(include) > 0 || len(exclude) > 0
+	if filtered && !all {
+		Die("The --tag and --not-tag filters can only be used with --all")
+	}
+
+	if !all && !recursive {
+		name := timerName(args)
+
+		slog.Debug("Clearing timer", "Name", name)
+		clearTimer(name, nil)
+		return
+	}
+
+	// Each timer is cleared under its own lock, which removing its lock file requires. A timer created after
+	// the scan is left alone.
+	unlockStore := lockStore()
+	defer unlockStore()
+
+	nts, err := store.LoadAll()
+	MaybeDie(err)
 
 	if all {
-		slog.Debug("Clearing all timers")
-		err = timer.ClearAll(cacheDir)
-		MaybeDie(err)
-	} else {
-		var name string
-		if len(args) == 0 {
-			name = timer.DEFAULT_TIMER_NAME
-		} else {
-			name = args[0]
+		slog.Debug("Clearing timers", "Tags", include, "NotTags", exclude)
+		for _, nt := range timer.FilterTags(nts, include, exclude) {
+			clearTimer(nt.Name, func(t *timer.Timer) bool {
+				return t.MatchTags(include, exclude)
+			})
 		}
+		return
+	}
 
-		slog.Debug("Clearing timer", "Name", name)
-		err = timer.Clear(name, cacheDir)
-		MaybeDie(err)
+	name := timerName(args)
+	subtree := timer.SelectSubtree(nts, name)
+	if len(subtree) == 0 {
+		Die("No timers found at or below %v", name)
+	}
+
+	slog.Debug("Clearing timer tree", "Name", name, "Count", len(subtree))
+	for _, nt := range subtree {
+		clearTimer(nt.Name, func(t *timer.Timer) bool {
+			return true
+		})
 	}
 }
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+// nodeFilter picks which timers are offered when completing a timer name.
+type nodeFilter func(node *timer.Node) bool
+
+// anyNode offers every timer as well as the parents of hierarchical names, for the commands that accept a
+// whole subtree.
+func anyNode(node *timer.Node) bool {
+	return true
+}
+
+func anyTimer(node *timer.Node) bool {
+	return node.Ticks != nil
+}
+
+func runningTimer(node *timer.Node) bool {
+	return node.Ticks != nil && node.Ticks.IsRunning()
+}
+
+func stoppedTimer(node *timer.Node) bool {
+	return node.Ticks != nil && !node.Ticks.IsRunning()
+}
+
+// completeTimerNames suggests the stored timers that pass the filter for a command's single name argument.
+// Each suggestion is described by the timer's running state and elapsed time.
+func completeTimerNames(filter nodeFilter) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 || store == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		nts, err := timer.Snapshot(store)
+		if err != nil {
+			cobra.CompDebugln(fmt.Sprintf("Couldn't load timers: %v", err), false)
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := []cobra.Completion{}
+		for _, root := range timer.BuildTree(nts) {
+			root.Walk(func(node *timer.Node, depth int) {
+				if !strings.HasPrefix(node.Path, toComplete) || !filter(node) {
+					return
+				}
+				completions = append(completions, cobra.CompletionWithDesc(node.Path, describeNode(node)))
+			})
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// describeNode summarizes a timer for shell completion, like "running, 5m3s". Parents that aren't timers
+// themselves show the total of everything below them.
+func describeNode(node *timer.Node) string {
+	state := "stopped"
+	if node.IsRunning() {
+		state = "running"
+	}
+
+	description := fmt.Sprintf("%s, %s", state, node.Elapsed().Round(timer.Precision))
+	if node.Ticks == nil {
+		description = "group, " + description
+	}
+	return description
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/dusktreader/gowatch/config"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:	"config",
+	Short:	"Manage the config file",
+	Long:	fmt.Sprintf("Get, set and list the settings in the config file (keys: %s, %s<command>.<flag>)", strings.Join(config.Keys, ", "), config.COMMANDS_PREFIX),
+}
+
+var configGetCmd = &cobra.Command{
+	Use:	"get <key>",
+	Short:	"Show a setting",
+	Long:	"Show the effective value of a setting, taken from the environment, the config file or the default",
+	Args:	cobra.ExactArgs(1),
+	Run:	configGetMain,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:	"set <key> [value]",
+	Short:	"Change a setting",
+	Long:	"Change a setting in the config file. Leave out the value to remove the setting",
+	Args:	cobra.RangeArgs(1, 2),
+	Run:	configSetMain,
+}
+
+var configListCmd = &cobra.Command{
+	Use:	"list",
+	Short:	"List all settings",
+	Long:	"List the effective value of every setting and where it came from",
+	Args:	cobra.NoArgs,
+	Run:	configListMain,
+}
+
+func isConfigCommand(cmd *cobra.Command) bool {
+	return cmd == configCmd || cmd.Parent() == configCmd
+}
+
+func configGetMain(cmd *cobra.Command, args []string){
+	key := args[0]
+	for _, entry := range cfg.Entries() {
+		if entry.Key == key {
+			fmt.Println(entry.Value)
+			return
+		}
+	}
+
+	// Per-command flags that aren't set have no value, but the key may still be valid.
+	_, err := cfg.Get(key)
+	MaybeDie(err)
+}
+
+// checkCommandFlag makes sure a per-command key names a real command and flag, and that the value suits it.
+func checkCommandFlag(key string, value string) {
+	rest, ok := strings.CutPrefix(key, config.COMMANDS_PREFIX)
+	if !ok {
+		return
+	}
+	command, name, _ := strings.Cut(rest, ".")
+
+	target, _, err := rootCmd.Find([]string{command})
+	if err != nil || target == rootCmd || target.Parent() != rootCmd {
+		Die("Unknown command %v in config key %v", command, key)
+	}
+
+	flag := target.Flags().Lookup(name)
+	if flag == nil {
+		flag = target.PersistentFlags().Lookup(name)
+	}
+	if flag == nil {
+		flag = target.InheritedFlags().Lookup(name)
+	}
+	if flag == nil {
+		Die("Unknown flag %v for the %v command in config key %v", name, command, key)
+	}
+
+	// The target command won't run, so its flag can be used to check the value.
+	if value != "" {
+		err = flag.Value.Set(value)
+		if err != nil {
+			Die("Invalid value %q for the %v flag of the %v command: %v", value, name, command, err)
+		}
+	}
+}
+
+func configSetMain(cmd *cobra.Command, args []string){
+	key := args[0]
+	value := ""
+	if len(args) > 1 {
+		value = args[1]
+	}
+
+	checkCommandFlag(key, value)
+	for _, prefix := range []string{config.ALIASES_PREFIX, config.MACROS_PREFIX} {
+		if name, ok := strings.CutPrefix(key, prefix); ok && value != "" && isBuiltinCommand(name) {
+			Die("Can't define %v: %v is already a gowatch command", key, name)
+		}
+	}
+
+	path := config.Path()
+	c, err := config.Load(path)
+	MaybeDie(err)
+
+	slog.Debug("Setting config", "Key", key, "Value", value, "Path", path)
+	err = c.Set(key, value)
+	MaybeDie(err)
+
+	err = c.Save(path)
+	MaybeDie(err)
+}
+
+func configListMain(cmd *cobra.Command, args []string){
+	entries := cfg.Entries()
+	width := 0
+	for _, entry := range entries {
+		width = max(width, len(entry.Key))
+	}
+
+	fmt.Printf("# %s\n", config.Path())
+	for _, entry := range entries {
+		fmt.Printf("%-*s = %s (%s)\n", width, entry.Key, entry.Value, entry.Source)
+	}
+}
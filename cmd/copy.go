@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	copyCmd.PersistentFlags().BoolP("force", "F", false, "Replace the destination timer if it already exists")
+	rootCmd.AddCommand(copyCmd)
+}
+
+var copyCmd = &cobra.Command{
+	Use:	"copy <source> <destination>",
+	Short:	"Copy a timer",
+	Long:	"Copy a stopped timer, refusing to replace an existing timer unless forced",
+	Args:	cobra.ExactArgs(2),
+	Run:	copyMain,
+}
+
+func copyMain(cmd *cobra.Command, args []string){
+	force, err := cmd.Flags().GetBool("force")
+	MaybeDie(err)
+
+	slog.Debug("Copying timer", "From", args[0], "To", args[1])
+	err = timer.Copy(store, args[0], args[1], force)
+	MaybeDie(err)
+}
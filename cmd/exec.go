@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	execCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the interval")
+	rootCmd.AddCommand(execCmd)
+}
+
+var execCmd = &cobra.Command{
+	Use:	"exec [name] -- <command> [args...]",
+	Short:	"Time a command",
+	Long:	"Start a named timer, run a command, and stop the timer when the command exits with its exit code",
+	Args:	cobra.MinimumNArgs(1),
+	Run:	execMain,
+}
+
+// EXIT_NOT_FOUND is the exit code for a command that couldn't be run, as shells use.
+const EXIT_NOT_FOUND = 127
+
+// quoteCommand joins a command line back together, quoting the arguments a shell would split.
+func quoteCommand(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`;&|<>()*?[]#~") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// exitCode returns the code a shell would report for the finished command.
+func exitCode(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
+
+// runChild runs the command with the terminal passed through, forwarding the signals gowatch receives until
+// it exits. Interrupts and quits are caught but not forwarded: they come from the terminal, which already
+// sends them to the child because it shares gowatch's process group, and forwarding would deliver them twice.
+func runChild(args []string) int {
+	child := exec.Command(args[0], args[1:]...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(signals)
+
+	err := child.Start()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "There was an error:", err)
+		return EXIT_NOT_FOUND
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == os.Interrupt || sig == syscall.SIGQUIT {
+					slog.Debug("Leaving terminal signal to the child", "Signal", sig)
+					continue
+				}
+				slog.Debug("Forwarding signal", "Signal", sig)
+				_ = child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err = child.Wait()
+	close(done)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		fmt.Fprintln(os.Stderr, "There was an error:", err)
+	}
+	return exitCode(child.ProcessState)
+}
+
+func execMain(cmd *cobra.Command, args []string){
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 {
+		Die("Separate the command from the timer name with --, like: gowatch exec build -- make build")
+	}
+	if dash > 1 {
+		Die("Give at most one timer name before --")
+	}
+	if dash == len(args) {
+		Die("Give the command to run after --")
+	}
+
+	name := timerName(args[:dash])
+	command := args[dash:]
+
+	exclusive := cfg.ExclusiveMode()
+	unlockStore := func() {}
+	if exclusive {
+		unlockStore = lockStore()
+	}
+
+	var started time.Time
+	paused := startTimer(name, timer.RealNowProvider{}, exclusive, func(t *timer.Timer) error {
+		t.Current().AddNote(note)
+		started = t.Current().Start
+		return nil
+	})
+	unlockStore()
+	reportPaused(paused)
+
+	slog.Debug("Running command", "Name", name, "Command", command)
+	code := runChild(command)
+
+	unlock := lockTimer(name)
+	t, err := store.Load(name)
+	MaybeDie(err)
+
+	// Another command may have stopped or reset the timer while the child ran.
+	var segment *timer.Segment
+	for i := range t.Segments {
+		if t.Segments[i].Start.Equal(started) {
+			segment = &t.Segments[i]
+		}
+	}
+	if segment == nil {
+		slog.Warn("The timer's interval disappeared while the command ran", "Name", name)
+	} else {
+		stopped := segment.IsOpen()
+		if stopped {
+			slog.Debug("Stopping timer", "Name", name)
+			err = t.Stop()
+			MaybeDie(err)
+		}
+		segment.RecordExit(quoteCommand(command), code)
+
+		err = store.Dump(name, t)
+		MaybeDie(err)
+		if stopped {
+			recordStop(name, segment.End)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, segment.Elapsed().Round(timer.Precision))
+	}
+	unlock()
+
+	os.Exit(code)
+}
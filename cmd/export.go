@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dusktreader/gowatch/interchange"
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	exportCmd.PersistentFlags().String(
+		"as",
+		"",
+		fmt.Sprintf("The export format (%s); guessed from --file when omitted", strings.Join(interchange.ExportFormats, "|")),
+	)
+	exportCmd.PersistentFlags().StringP("file", "f", "", "Write the export to this file instead of stdout")
+	exportCmd.PersistentFlags().String("since", "", "Only export time after this moment, like 2006-01-02 or yesterday")
+	exportCmd.PersistentFlags().String("until", "", "Only export time before this moment, like 2006-01-02 or today")
+	addTagFilterFlags(exportCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:	"export [names...]",
+	Short:	"Export recorded intervals",
+	Long:	"Export the recorded intervals of all timers, or of the named timers and everything below them, as CSV, JSON or iCalendar",
+	Run:	exportMain,
+}
+
+// exportFormat picks the export format from --as, falling back to the extension of --file and then JSON.
+func exportFormat(as string, path string) string {
+	if as != "" {
+		return as
+	}
+
+	extension := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if slices.Contains(interchange.ExportFormats, extension) {
+		return extension
+	}
+	return interchange.JSON
+}
+
+func exportMain(cmd *cobra.Command, args []string){
+	as, err := cmd.Flags().GetString("as")
+	MaybeDie(err)
+
+	path, err := cmd.Flags().GetString("file")
+	MaybeDie(err)
+
+	format := exportFormat(as, path)
+	MaybeDie(interchange.CheckExportFormat(format))
+
+	since := parseMomentFlag(cmd, "since")
+	until := parseMomentFlag(cmd, "until")
+
+	for _, name := range args {
+		MaybeDie(timer.CheckName(name))
+	}
+
+	nts, err := timer.Snapshot(store)
+	MaybeDie(err)
+
+	if len(args) > 0 {
+		selected := []*timer.NamedTimer{}
+		for _, nt := range nts {
+			if slices.ContainsFunc(args, func(name string) bool { return timer.IsWithin(nt.Name, name) }) {
+				selected = append(selected, nt)
+			}
+		}
+		nts = selected
+	}
+
+	include, exclude := tagFilters(cmd)
+	nts = timer.FilterTags(nts, include, exclude)
+
+	slog.Debug("Exporting timers", "Format", format, "Since", since, "Until", until, "Count", len(nts))
+	document := interchange.NewDocument(nts, since, until, timer.RealNowProvider{})
+
+	var w io.Writer = os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		MaybeDie(err)
+		defer file.Close()
+		w = file
+	}
+
+	err = document.Write(w, format)
+	MaybeDie(err)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dusktreader/gowatch/interchange"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	importCmd.PersistentFlags().String(
+		"from",
+		"",
+		fmt.Sprintf("The import format (%s); guessed from the file extension when omitted", strings.Join(interchange.ImportFormats, "|")),
+	)
+	importCmd.PersistentFlags().String(
+		"on-conflict",
+		interchange.ON_CONFLICT_SKIP,
+		fmt.Sprintf("What to do with timers that already exist (%s)", strings.Join(interchange.ConflictModes, "|")),
+	)
+	importCmd.PersistentFlags().BoolP("dry-run", "n", false, "Show what would be imported without changing any timers")
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:	"import <file>...",
+	Short:	"Import timers",
+	Long:	"Import timers from Timewarrior data files, Toggl CSV exports or gowatch's own JSON export. Use - to read stdin",
+	Args:	cobra.MinimumNArgs(1),
+	Run:	importMain,
+}
+
+func readImport(path string, format string) *interchange.Document {
+	if format == "" {
+		format = interchange.GuessImportFormat(path)
+		if format == "" {
+			Die("Can't tell the format of %v; choose one with --from", path)
+		}
+	}
+	MaybeDie(interchange.CheckImportFormat(format))
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		MaybeDie(err)
+		defer file.Close()
+		r = file
+	}
+
+	slog.Debug("Reading import", "Path", path, "Format", format)
+	document, err := interchange.Read(r, format, time.Local)
+	if err != nil {
+		Die("Couldn't import %v: %v", path, err)
+	}
+	return document
+}
+
+func importMain(cmd *cobra.Command, args []string){
+	format, err := cmd.Flags().GetString("from")
+	MaybeDie(err)
+
+	onConflict, err := cmd.Flags().GetString("on-conflict")
+	MaybeDie(err)
+	MaybeDie(interchange.CheckConflictMode(onConflict))
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	MaybeDie(err)
+
+	documents := make([]*interchange.Document, 0, len(args))
+	for _, path := range args {
+		documents = append(documents, readImport(path, format))
+	}
+
+	imported := 0
+	skipped := 0
+	for _, document := range documents {
+		changes, err := interchange.Import(store, document, onConflict, dryRun)
+		MaybeDie(err)
+
+		for _, change := range changes {
+			name := change.Name
+			if change.Name != change.Source {
+				name = fmt.Sprintf("%s (from %s)", change.Name, change.Source)
+			}
+			if change.Action == interchange.SKIPPED {
+				fmt.Printf("%-8s %s\n", change.Action, name)
+				skipped++
+				continue
+			}
+			fmt.Printf("%-8s %s: %d intervals, %v", change.Action, name, change.Intervals, change.Duration)
+			if change.Duplicates > 0 {
+				fmt.Printf(" (%d already present)", change.Duplicates)
+			}
+			if change.Overlaps > 0 {
+				fmt.Printf(" (%d overlapping recorded time, skipped)", change.Overlaps)
+			}
+			fmt.Println()
+			imported++
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Would import %d timers (%d skipped); nothing was changed\n", imported, skipped)
+	} else {
+		fmt.Printf("Imported %d timers (%d skipped)\n", imported, skipped)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	lapCmd.PersistentFlags().StringP("name", "n", "", "Name the lap instead of numbering it")
+	lapCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the lap")
+	rootCmd.AddCommand(lapCmd)
+}
+
+var lapCmd = &cobra.Command{
+	Use:	"lap",
+	Short:	"Record a lap",
+	Long:	"Record a lap on a running named timer",
+	Args:	cobra.MaximumNArgs(1),
+	ValidArgsFunction:	completeTimerNames(runningTimer),
+	Run:	lapMain,
+}
+
+func lapMain(cmd *cobra.Command, args []string){
+	lapName, err := cmd.Flags().GetString("name")
+	MaybeDie(err)
+
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	name := timerName(args)
+
+	unlock := lockTimer(name)
+	defer unlock()
+
+	t, err := store.Load(name)
+	MaybeDie(err)
+
+	slog.Debug("Recording lap", "Name", name)
+	lap, err := t.Lap(lapName)
+	MaybeDie(err)
+	lap.Note = note
+
+	err = store.Dump(name, t)
+	MaybeDie(err)
+
+	slog.Debug("Lap recorded", "Name", name, "Timer", t)
+	fmt.Println(t.LapString(len(t.Laps) - 1))
+}
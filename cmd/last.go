@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(lastCmd)
+}
+
+var lastCmd = &cobra.Command{
+	Use:	"last",
+	Short:	"Show the last used timer",
+	Long:	"Show which timer was started or stopped most recently, and when",
+	Args:	cobra.NoArgs,
+	Run:	lastMain,
+}
+
+func lastMain(cmd *cobra.Command, args []string){
+	state, err := store.LoadState()
+	MaybeDie(err)
+
+	event, started := state.Last()
+	if event == nil {
+		fmt.Fprintln(os.Stderr, "No timer has been started or stopped yet")
+		return
+	}
+
+	action := "stopped"
+	if started {
+		action = "started"
+	}
+	fmt.Printf("%s: %s at %s\n", event.Name, action, event.At.Format(time.RFC3339))
+}
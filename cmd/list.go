@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/dusktreader/gowatch/output"
 	"github.com/dusktreader/gowatch/timer"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	listCmd.PersistentFlags().BoolP("full", "f", false, "Show the full timers")
+	listCmd.PersistentFlags().String("format", "", "Render with a Go template (see README for fields and helpers)")
+	addTagFilterFlags(listCmd)
 	rootCmd.AddCommand(listCmd)
 }
 
@@ -26,16 +30,42 @@ func listMain(cmd *cobra.Command, args []string){
 	full, err := cmd.Flags().GetBool("full")
 	MaybeDie(err)
 
-	cacheDir := timer.GetCacheDir()
+	format, err := cmd.Flags().GetString("output")
+	MaybeDie(err)
+
+	tmpl := formatTemplate(cmd)
 
 	slog.Debug("Loading all timers")
-	nts, err := timer.LoadAll(cacheDir)
+	nts, err := timer.Snapshot(store)
 	MaybeDie(err)
 
+	include, exclude := tagFilters(cmd)
+	nts = timer.FilterTags(nts, include, exclude)
+
 	if len(nts) == 0 {
 		fmt.Fprintln(os.Stderr, "No timers found")
 	}
 
+	if tmpl != nil {
+		slog.Debug("Listing templated timers", "Count", len(nts))
+		err = output.WriteTemplate(os.Stdout, tmpl, output.NewRecords(nts))
+		MaybeDie(err)
+		return
+	}
+
+	if format != output.TEXT {
+		slog.Debug("Listing structured timers", "Count", len(nts), "Format", format)
+		err = output.WriteRecords(os.Stdout, format, output.NewRecords(nts))
+		MaybeDie(err)
+		return
+	}
+
+	if timer.IsHierarchical(nts) {
+		slog.Debug("Listing timer tree", "Count", len(nts))
+		printTree(timer.BuildTree(nts), full)
+		return
+	}
+
 	slog.Debug("Computing alignment for names")
 	maxWidth := 0
 	for _, nt := range nts {
@@ -44,12 +74,43 @@ func listMain(cmd *cobra.Command, args []string){
 
 	slog.Debug("Listing timers", "Count", len(nts))
 	for _, nt := range nts {
+		var mark string
+		if nt.Ticks.IsExpired() {
+			mark = " [expired]"
+		}
+
 		if full {
 			slog.Debug("Showing full timer", "Name", nt.Name)
-			fmt.Printf("%*s: %s\n", maxWidth, nt.Name, nt.Ticks)
+			fmt.Printf("%*s: %s%s\n", maxWidth, nt.Name, nt.Ticks, mark)
 		} else {
 			slog.Debug("Showing compact timer", "Name", nt.Name)
-			fmt.Printf("%*s: %s\n", maxWidth, nt.Name, nt.Ticks.ElapsedString())
+			fmt.Printf("%*s: %s%s\n", maxWidth, nt.Name, nt.Ticks.ElapsedString(), mark)
 		}
 	}
 }
+
+func printTree(nodes []*timer.Node, full bool) {
+	maxWidth := 0
+	for _, node := range nodes {
+		node.Walk(func(n *timer.Node, depth int) {
+			maxWidth = max(maxWidth, 2 * depth + len(n.Name))
+		})
+	}
+
+	for _, node := range nodes {
+		node.Walk(func(n *timer.Node, depth int) {
+			label := strings.Repeat("  ", depth) + n.Name
+
+			var mark string
+			if n.Ticks != nil && n.Ticks.IsExpired() {
+				mark = " [expired]"
+			}
+
+			if full && n.Ticks != nil && len(n.Children) == 0 {
+				fmt.Printf("%-*s: %s%s\n", maxWidth, label, n.Ticks, mark)
+			} else {
+				fmt.Printf("%-*s: %s%s\n", maxWidth, label, n.Elapsed().Round(timer.Precision), mark)
+			}
+		})
+	}
+}
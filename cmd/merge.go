@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	mergeCmd.PersistentFlags().String("into", "", "The timer to merge into (required)")
+	mergeCmd.PersistentFlags().BoolP("force", "F", false, "Replace the destination timer if it already exists")
+	MaybeDie(mergeCmd.MarkPersistentFlagRequired("into"))
+	rootCmd.AddCommand(mergeCmd)
+}
+
+var mergeCmd = &cobra.Command{
+	Use:	"merge <names...> --into <name>",
+	Short:	"Merge timers",
+	Long:	"Combine the intervals of stopped timers into one timer and clear the originals",
+	Args:	cobra.MinimumNArgs(1),
+	Run:	mergeMain,
+}
+
+func mergeMain(cmd *cobra.Command, args []string){
+	into, err := cmd.Flags().GetString("into")
+	MaybeDie(err)
+
+	force, err := cmd.Flags().GetBool("force")
+	MaybeDie(err)
+
+	slog.Debug("Merging timers", "Names", args, "Into", into)
+	err = timer.Merge(store, args, into, force)
+	MaybeDie(err)
+
+	t, err := store.Load(into, true)
+	MaybeDie(err)
+	fmt.Println(t.ElapsedString())
+}
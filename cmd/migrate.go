@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	migrateCmd.PersistentFlags().String("from", "", "Directory holding the per-file timers to import (default: the cache_dir setting)")
+	migrateCmd.PersistentFlags().BoolP("force", "F", false, "Overwrite timers that already exist in the store")
+	rootCmd.AddCommand(migrateCmd)
+}
+
+var migrateCmd = &cobra.Command{
+	Use:	"migrate",
+	Short:	"Migrate timers into the store",
+	Long:	"Import per-file timers from the cache directory into the configured store",
+	Args:	cobra.NoArgs,
+	Run:	migrateMain,
+}
+
+func migrateMain(cmd *cobra.Command, _ []string){
+	from, err := cmd.Flags().GetString("from")
+	MaybeDie(err)
+	if from == "" {
+		from = cfg.StorageDir()
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	MaybeDie(err)
+
+	if fs, ok := store.(*timer.FileStore); ok && filepath.Clean(fs.CacheDir) == filepath.Clean(from) {
+		Die("The store already reads timers from %v; select another store kind with the store setting or GOWATCH_STORE", from)
+	}
+
+	slog.Debug("Loading timers to migrate", "From", from)
+	nts, err := timer.NewFileStore(from).LoadAll()
+	MaybeDie(err)
+
+	migrated := 0
+	skipped := 0
+	for _, nt := range nts {
+		unlock := lockTimer(nt.Name)
+
+		exists, err := store.Exists(nt.Name)
+		MaybeDie(err)
+
+		if exists && !force {
+			slog.Warn("Skipping timer that already exists in the store", "Name", nt.Name)
+			skipped++
+		} else {
+			slog.Debug("Migrating timer", "Name", nt.Name)
+			err = store.Dump(nt.Name, nt.Ticks)
+			MaybeDie(err)
+			migrated++
+		}
+
+		unlock()
+	}
+
+	fmt.Printf("Migrated %d timers (%d skipped)\n", migrated, skipped)
+}
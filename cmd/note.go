@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	noteCmd.PersistentFlags().IntP("segment", "s", 0, "Number of the interval to annotate, as listed by show --full (default: the last one)")
+	noteCmd.PersistentFlags().IntP("lap", "l", 0, "Number of the lap to annotate instead of an interval")
+	rootCmd.AddCommand(noteCmd)
+}
+
+var noteCmd = &cobra.Command{
+	Use:	"note [name] <text>",
+	Short:	"Annotate a timer",
+	Long:	"Replace the note on an interval or lap of a named timer. An empty text removes the note.",
+	Args:	cobra.RangeArgs(1, 2),
+	Run:	noteMain,
+}
+
+func noteMain(cmd *cobra.Command, args []string){
+	segment, err := cmd.Flags().GetInt("segment")
+	MaybeDie(err)
+
+	lap, err := cmd.Flags().GetInt("lap")
+	MaybeDie(err)
+
+	if segment < 0 || lap < 0 {
+		Die("Interval and lap numbers must be positive")
+	}
+	if segment > 0 && lap > 0 {
+		Die("The --segment and --lap flags can't be combined")
+	}
+
+	name := timerName(args[:len(args) - 1])
+	text := args[len(args) - 1]
+
+	unlock := lockTimer(name)
+	defer unlock()
+
+	t, err := store.Load(name, true)
+	MaybeDie(err)
+
+	if lap > 0 && len(t.Laps) == 0 {
+		Die("Timer %v has no laps to annotate", name)
+	} else if lap > len(t.Laps) {
+		Die("Timer %v has no lap %d", name, lap)
+	} else if lap == 0 && len(t.Segments) == 0 {
+		Die("Timer %v has no intervals to annotate", name)
+	} else if segment > len(t.Segments) {
+		Die("Timer %v has no interval %d", name, segment)
+	}
+
+	if lap > 0 {
+		slog.Debug("Annotating lap", "Name", name, "Lap", lap)
+		err = t.SetLapNote(lap - 1, text)
+	} else if segment > 0 {
+		slog.Debug("Annotating interval", "Name", name, "Segment", segment)
+		err = t.SetNote(segment - 1, text)
+	} else {
+		slog.Debug("Annotating last interval", "Name", name)
+		err = t.SetNote(-1, text)
+	}
+	MaybeDie(err)
+
+	err = store.Dump(name, t)
+	MaybeDie(err)
+}
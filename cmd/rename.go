@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	renameCmd.PersistentFlags().BoolP("force", "F", false, "Replace the new timer if it already exists")
+	rootCmd.AddCommand(renameCmd)
+}
+
+var renameCmd = &cobra.Command{
+	Use:	"rename <old> <new>",
+	Short:	"Rename a timer",
+	Long:	"Rename a stopped timer, refusing to replace an existing timer unless forced",
+	Args:	cobra.ExactArgs(2),
+	Run:	renameMain,
+}
+
+func renameMain(cmd *cobra.Command, args []string){
+	force, err := cmd.Flags().GetBool("force")
+	MaybeDie(err)
+
+	slog.Debug("Renaming timer", "From", args[0], "To", args[1])
+	err = timer.Rename(store, args[0], args[1], force)
+	MaybeDie(err)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dusktreader/gowatch/report"
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	reportCmd.PersistentFlags().String("since", "", "Only count time after this moment, like 2006-01-02 or yesterday")
+	reportCmd.PersistentFlags().String("until", "", "Only count time before this moment, like 2006-01-02 or today")
+	reportCmd.PersistentFlags().String(
+		"by",
+		report.BY_DAY,
+		fmt.Sprintf("How to group the recorded time (%s)", strings.Join(report.Groupings, "|")),
+	)
+	addTagFilterFlags(reportCmd)
+	rootCmd.AddCommand(reportCmd)
+}
+
+var reportCmd = &cobra.Command{
+	Use:	"report [name]",
+	Short:	"Summarize recorded time",
+	Long:	"Summarize the time recorded by all timers, or by a named timer and everything below it",
+	Args:	cobra.MaximumNArgs(1),
+	Run:	reportMain,
+}
+
+// parseMomentFlag parses a flag holding a moment, returning the zero time if the flag is empty.
+func parseMomentFlag(cmd *cobra.Command, flag string) time.Time {
+	text, err := cmd.Flags().GetString(flag)
+	MaybeDie(err)
+	if text == "" {
+		return time.Time{}
+	}
+
+	moment, err := timer.ParseMoment(text, time.Now())
+	MaybeDie(err)
+	return moment
+}
+
+func reportMain(cmd *cobra.Command, args []string){
+	by, err := cmd.Flags().GetString("by")
+	MaybeDie(err)
+
+	format, err := cmd.Flags().GetString("output")
+	MaybeDie(err)
+
+	since := parseMomentFlag(cmd, "since")
+	until := parseMomentFlag(cmd, "until")
+
+	nts, err := timer.Snapshot(store)
+	MaybeDie(err)
+
+	if len(args) > 0 {
+		nts = timer.SelectSubtree(nts, timerName(args))
+	}
+
+	include, exclude := tagFilters(cmd)
+	nts = timer.FilterTags(nts, include, exclude)
+
+	slog.Debug("Building report", "By", by, "Since", since, "Until", until, "Count", len(nts))
+	r, err := report.Build(nts, report.Options{
+		Since: since,
+		Until: until,
+		By: by,
+	})
+	MaybeDie(err)
+
+	err = r.Write(os.Stdout, format)
+	MaybeDie(err)
+}
@@ -8,6 +8,7 @@ import (
 )
 
 func init() {
+	resetCmd.PersistentFlags().BoolP("recursive", "r", false, "Also reset every timer below the named one")
 	rootCmd.AddCommand(resetCmd)
 }
 
@@ -16,25 +17,58 @@ var resetCmd = &cobra.Command{
 	Short:	"Reset a timer",
 	Long:	"Reset a named timer",
 	Args:	cobra.MaximumNArgs(1),
+	ValidArgsFunction:	completeTimerNames(anyNode),
 	Run:	resetMain,
 }
 
-func resetMain(_ *cobra.Command, args []string){
-	var name string
-	if len(args) == 0 {
-		name = timer.DEFAULT_TIMER_NAME
-	} else {
-		name = args[0]
+func resetMain(cmd *cobra.Command, args []string){
+	recursive, err := cmd.Flags().GetBool("recursive")
+	MaybeDie(err)
+
+	name := timerName(args)
+
+	if !recursive {
+		resetTimer(name, false)
+		return
+	}
+
+	// The store lock keeps multi-timer changes out while the subtree is reset.
+	unlockStore := lockStore()
+	defer unlockStore()
+
+	nts, err := store.LoadAll()
+	MaybeDie(err)
+
+	subtree := timer.SelectSubtree(nts, name)
+	if len(subtree) == 0 {
+		Die("No timers found at or below %v", name)
+	}
+	for _, nt := range subtree {
+		resetTimer(nt.Name, true)
 	}
+}
+
+// resetTimer resets a timer under its lock. When scanned, the timer was picked from an earlier scan, so it is
+// only reset if it still exists; otherwise resetting would bring a cleared timer back.
+func resetTimer(name string, scanned bool) {
+	unlock := lockTimer(name)
+	defer unlock()
 
-	cacheDir := timer.GetCacheDir()
+	if scanned {
+		exists, err := store.Exists(name)
+		MaybeDie(err)
+		if !exists {
+			slog.Debug("Timer no longer exists", "Name", name)
+			return
+		}
+	}
 
-	t, err := timer.Load(name, cacheDir)
+	t, err := store.Load(name)
 	MaybeDie(err)
 
 	slog.Debug("Resetting timer", "Name", name)
 	t.Reset()
 
-	err = t.Dump(name, cacheDir)
+	err = store.Dump(name, t)
 	MaybeDie(err)
 }
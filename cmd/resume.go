@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	resumeCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the new interval")
+	addMomentFlags(resumeCmd, "Resume")
+	addExclusiveFlag(resumeCmd)
+	rootCmd.AddCommand(resumeCmd)
+}
+
+var resumeCmd = &cobra.Command{
+	Use:	"resume",
+	Short:	"Resume the last stopped timer",
+	Long:	"Start the timer that was stopped most recently",
+	Args:	cobra.NoArgs,
+	Run:	resumeMain,
+}
+
+func resumeMain(cmd *cobra.Command, args []string){
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	np := momentProvider(cmd)
+
+	state, err := store.LoadState()
+	MaybeDie(err)
+	if state.Stopped == nil {
+		Die("No timer has been stopped yet")
+	}
+	name := state.Stopped.Name
+
+	exclusive := exclusiveMode(cmd)
+	if exclusive {
+		unlockStore := lockStore()
+		defer unlockStore()
+	}
+
+	// The timer is checked again under its lock, once startTimer holds it. A timer that was started meanwhile
+	// is refused by startTimer itself.
+	slog.Debug("Resuming timer", "Name", name)
+	paused := startTimer(name, np, exclusive, func(t *timer.Timer) error {
+		exists, err := store.Exists(name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("The last stopped timer, %v, no longer exists", name)
+		}
+		t.Current().AddNote(note)
+		return nil
+	})
+	fmt.Printf("Resumed %s\n", name)
+	reportPaused(paused)
+}
@@ -5,15 +5,29 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"strings"
+	"text/template"
+	"time"
 
+	"github.com/dusktreader/gowatch/config"
+	"github.com/dusktreader/gowatch/output"
 	"github.com/dusktreader/gowatch/timer"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Show verbose logging output")
+	rootCmd.PersistentFlags().StringP(
+		"output",
+		"o",
+		output.TEXT,
+		fmt.Sprintf("Output format for read commands (%s)", strings.Join(output.Formats, "|")),
+	)
 }
 
+var store timer.Store
+var cfg *config.Config
+
 var rootCmd = &cobra.Command{
 	Use:				"gowatch",
 	Short:				"Go Stopwatch",
@@ -23,12 +37,45 @@ var rootCmd = &cobra.Command{
 }
 
 func preRun(cmd *cobra.Command, args []string) {
-	err := timer.EnsureDir(timer.GetCacheDir())
+	var err error
+	cfg, err = config.Load(config.Path())
+	MaybeDie(err)
+
+	// The config commands only need the config, and must still run when it is invalid so that it can be fixed.
+	if isConfigCommand(cmd) {
+		setupLogging(cmd)
+		err = cfg.Check()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Warning:", err)
+		}
+		return
+	}
+
+	err = cfg.Check()
+	MaybeDie(err)
+
+	applyCommandDefaults(cmd)
+	setupLogging(cmd)
+	timer.Precision = cfg.DisplayPrecision()
+
+	cacheDir := cfg.StorageDir()
+	err = timer.EnsureDir(cacheDir)
 	MaybeDie(err)
 
 	err = timer.EnsureDir(timer.GetConfigDir())
 	MaybeDie(err)
 
+	format, err := cmd.Flags().GetString("output")
+	MaybeDie(err)
+	err = output.CheckFormat(format)
+	MaybeDie(err)
+
+	slog.Debug("Opening store", "Kind", cfg.StoreKind(), "Location", cacheDir)
+	store, err = timer.OpenStore(cfg.StoreKind(), cacheDir)
+	MaybeDie(err)
+}
+
+func setupLogging(cmd *cobra.Command) {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
 	verbose, err := cmd.Flags().GetBool("verbose")
@@ -40,6 +87,34 @@ func preRun(cmd *cobra.Command, args []string) {
 	}
 }
 
+// applyCommandDefaults gives the flags the user didn't set their values from the config: the configured
+// output format and the command's own default flags. The flags still count as unset afterwards. Only
+// top-level commands have default flags, so that "config list" doesn't pick up the flags of "list".
+func applyCommandDefaults(cmd *cobra.Command) {
+	defaults := map[string]string{"output": cfg.OutputFormat()}
+	if cmd.HasParent() && !cmd.Parent().HasParent() {
+		for name, value := range cfg.CommandFlags(cmd.Name()) {
+			defaults[name] = value
+		}
+	}
+
+	for name, value := range defaults {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			Die("Unknown flag %v for the %v command in %v", name, cmd.Name(), config.Path())
+		}
+		if flag.Changed {
+			continue
+		}
+
+		err := flag.Value.Set(value)
+		if err != nil {
+			Die("Invalid value %q for the %v flag of the %v command in %v: %v", value, name, cmd.Name(), config.Path(), err)
+		}
+		flag.DefValue = value
+	}
+}
+
 func rootMain(cmd *cobra.Command, args []string) {
 	_ = cmd.Help()
 }
@@ -57,7 +132,145 @@ func Die(msg string, flags ...interface{}) {
 	os.Exit(1)
 }
 
+// timerName returns the timer named by the first argument, or the default timer if there are none.
+func timerName(args []string) string {
+	if len(args) == 0 {
+		return cfg.DefaultTimerName()
+	}
+
+	err := timer.CheckName(args[0])
+	MaybeDie(err)
+	return args[0]
+}
+
+// formatTemplate returns the parsed --format template, or nil if none was given. The template takes the
+// place of an output format from the config, but not of one given with --output.
+func formatTemplate(cmd *cobra.Command) *template.Template {
+	text, err := cmd.Flags().GetString("format")
+	MaybeDie(err)
+	if text == "" {
+		return nil
+	}
+
+	format, err := cmd.Flags().GetString("output")
+	MaybeDie(err)
+	if cmd.Flags().Changed("output") && format != output.TEXT {
+		Die("The --format and --output flags can't be combined")
+	}
+
+	tmpl, err := output.ParseTemplate(text)
+	MaybeDie(err)
+	return tmpl
+}
+
+func addTagFilterFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSlice("tag", nil, "Only include timers with this tag (repeatable)")
+	cmd.PersistentFlags().StringSlice("not-tag", nil, "Exclude timers with this tag (repeatable)")
+}
+
+func tagFilters(cmd *cobra.Command) ([]string, []string) {
+	include, err := cmd.Flags().GetStringSlice("tag")
+	MaybeDie(err)
+
+	exclude, err := cmd.Flags().GetStringSlice("not-tag")
+	MaybeDie(err)
+
+	return include, exclude
+}
+
+func addMomentFlags(cmd *cobra.Command, action string) {
+	cmd.PersistentFlags().String("at", "", fmt.Sprintf("%s the timer at this moment instead of now, like 14:05", action))
+	cmd.PersistentFlags().Duration("ago", 0, fmt.Sprintf("%s the timer this long ago instead of now, like 10m", action))
+}
+
+// momentProvider returns the moment given with --at or --ago, or the current time when neither is given.
+func momentProvider(cmd *cobra.Command) timer.NowProvider {
+	at, err := cmd.Flags().GetString("at")
+	MaybeDie(err)
+
+	ago, err := cmd.Flags().GetDuration("ago")
+	MaybeDie(err)
+
+	if at != "" && ago != 0 {
+		Die("The --at and --ago flags can't be combined")
+	}
+	if ago < 0 {
+		Die("The --ago duration must not be negative: %v", ago)
+	}
+
+	now := time.Now()
+	moment := now.Add(-ago)
+	if at != "" {
+		moment, err = timer.ParseMoment(at, now)
+		MaybeDie(err)
+	}
+	if moment.After(now) {
+		Die("The moment %v is in the future", moment.Format(time.RFC3339))
+	}
+
+	slog.Debug("Using moment", "Moment", moment)
+	return timer.FixedNowProvider{Moment: moment}
+}
+
+func lockTimer(name string) func() {
+	lock, err := store.Lock(name)
+	MaybeDie(err)
+	return func() {
+		MaybeDie(lock.Unlock())
+	}
+}
+
+// lockStore takes the store-wide lock, for commands that change several timers at once and for readers that
+// must not see such a change half done.
+func lockStore() func() {
+	lock, err := store.LockStore()
+	MaybeDie(err)
+	return func() {
+		MaybeDie(lock.Unlock())
+	}
+}
+
+func addExclusiveFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolP("exclusive", "x", false, "Stop every other running timer (default from the exclusive setting)")
+}
+
+// exclusiveMode reports whether starting a timer should stop the others: --exclusive when it was given or set
+// for the command in the config, the exclusive setting otherwise.
+func exclusiveMode(cmd *cobra.Command) bool {
+	_, configured := cfg.CommandFlags(cmd.Name())["exclusive"]
+	if !cmd.Flags().Changed("exclusive") && !configured {
+		return cfg.ExclusiveMode()
+	}
+	exclusive, err := cmd.Flags().GetBool("exclusive")
+	MaybeDie(err)
+	return exclusive
+}
+
+// recordStart remembers the last started timer for the resume and last commands.
+func recordStart(name string, at time.Time) {
+	err := store.UpdateState(func(state *timer.State) {
+		state.RecordStart(name, at)
+	})
+	MaybeDie(err)
+}
+
+// recordStop remembers the last stopped timer for the resume and last commands.
+func recordStop(name string, at time.Time) {
+	err := store.UpdateState(func(state *timer.State) {
+		state.RecordStop(name, at)
+	})
+	MaybeDie(err)
+}
+
+// reportPaused tells the user which timers an exclusive start stopped.
+func reportPaused(paused []*timer.NamedTimer) {
+	for _, nt := range paused {
+		fmt.Fprintf(os.Stderr, "Paused %s: %s\n", nt.Name, nt.Ticks.ElapsedString())
+	}
+}
+
 func Execute() {
+	registerShortcuts()
 	err := rootCmd.Execute()
 	MaybeDie(err)
 }
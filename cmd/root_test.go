@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/dusktreader/gowatch/config"
+	"github.com/dusktreader/gowatch/output"
+	"github.com/spf13/cobra"
+)
+
+func TestFormatTemplate_ConfiguredOutput(t *testing.T) {
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	cfg = &config.Config{Output: output.JSON}
+
+	parent := &cobra.Command{Use: "gowatch"}
+	parent.PersistentFlags().String("output", output.TEXT, "")
+	child := &cobra.Command{Use: "show"}
+	child.PersistentFlags().String("format", "", "")
+	parent.AddCommand(child)
+
+	err := child.ParseFlags([]string{"--format", "{{.Name}}"})
+	if err != nil {
+		t.Fatalf("Couldn't parse flags: %v", err)
+	}
+	applyCommandDefaults(child)
+
+	format, _ := child.Flags().GetString("output")
+	if format != output.JSON {
+		t.Fatalf("applyCommandDefaults didn't apply the configured output: got %v", format)
+	}
+	if formatTemplate(child) == nil {
+		t.Errorf("formatTemplate didn't return the template when the output format came from the config")
+	}
+}
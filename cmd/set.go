@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	setCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the adjustment")
+	rootCmd.AddCommand(setCmd)
+}
+
+var setCmd = &cobra.Command{
+	Use:	"set [name] <duration>",
+	Short:	"Set the time on a timer",
+	Long:	"Set the elapsed time of a named timer to exactly a duration like 2h, recording the difference as a manual adjustment",
+	Args:	cobra.RangeArgs(1, 2),
+	Run:	setMain,
+}
+
+func setMain(cmd *cobra.Command, args []string){
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	name, d := adjustmentArgs(cmd, args)
+
+	unlock := lockTimer(name)
+	defer unlock()
+
+	t, err := store.Load(name)
+	MaybeDie(err)
+
+	slog.Debug("Setting time", "Name", name, "Duration", d)
+	err = t.SetElapsed(d, note)
+	MaybeDie(err)
+
+	err = store.Dump(name, t)
+	MaybeDie(err)
+
+	slog.Debug("Time set", "Name", name, "Timer", t)
+	fmt.Println(t.ElapsedString())
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dusktreader/gowatch/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// SHORTCUT_ANNOTATION marks the commands that were registered from aliases and macros.
+const SHORTCUT_ANNOTATION = "shortcut"
+
+// expanding holds the aliases and macros being run, so that one that refers to itself is caught.
+var expanding []string
+
+// registerShortcuts adds the aliases and macros from the config file to the root command, so that they show
+// up in help and shell completion like any other command.
+func registerShortcuts() {
+	c, err := config.Load(config.Path())
+	if err != nil {
+		// preRun reports the broken config file.
+		return
+	}
+
+	register := func(shortcuts map[string]string, kind string) {
+		names := make([]string, 0, len(shortcuts))
+		for name := range shortcuts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			definition := shortcuts[name]
+			if config.CheckShortcutName(name) != nil {
+				continue
+			}
+			if isBuiltinCommand(name) {
+				slog.Warn("Ignoring shortcut that shadows a command", "Kind", kind, "Name", name)
+				continue
+			}
+
+			rootCmd.AddCommand(&cobra.Command{
+				Use:	name,
+				Short:	fmt.Sprintf("%s for: %s", kind, definition),
+				Long:	fmt.Sprintf("Run %q, defined as %s in %s", definition, kind, config.Path()),
+				Annotations:	map[string]string{SHORTCUT_ANNOTATION: kind},
+				DisableFlagParsing:	true,
+				// The commands the shortcut runs do their own setup.
+				PersistentPreRun:	func(*cobra.Command, []string) {},
+				Run:	func(cmd *cobra.Command, args []string) {
+					runShortcut(name, kind, definition, args)
+				},
+			})
+		}
+	}
+	register(c.Aliases, "Alias")
+	register(c.Macros, "Macro")
+}
+
+// isBuiltinCommand reports whether name is taken by one of gowatch's own commands or their aliases.
+func isBuiltinCommand(name string) bool {
+	existing, _, err := rootCmd.Find([]string{name})
+	return err == nil && existing != rootCmd && existing.Annotations[SHORTCUT_ANNOTATION] == ""
+}
+
+// runShortcut runs every command of an alias or macro in turn, stopping at the first that fails. Extra
+// arguments are passed on to an alias's command.
+func runShortcut(name string, kind string, definition string, args []string) {
+	if slices.Contains(expanding, name) {
+		Die("%s %v refers to itself through %v", kind, name, strings.Join(append(expanding, name), " -> "))
+	}
+	expanding = append(expanding, name)
+	defer func() { expanding = expanding[:len(expanding) - 1] }()
+
+	steps, err := config.SplitCommands(definition)
+	MaybeDie(err)
+
+	if len(args) > 0 {
+		if len(steps) > 1 {
+			Die("Macro %v doesn't take arguments", name)
+		}
+		steps[0] = append(steps[0], args...)
+	}
+
+	for _, step := range steps {
+		slog.Debug("Running shortcut step", "Name", name, "Args", step)
+		resetFlags(rootCmd)
+		rootCmd.SetArgs(step)
+		err = rootCmd.Execute()
+		MaybeDie(err)
+	}
+}
+
+// resetFlags puts every flag back to its default, so that one step of a macro doesn't leak into the next.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			values := []string{}
+			text := strings.Trim(flag.DefValue, "[]")
+			if text != "" {
+				values = strings.Split(text, ",")
+			}
+			_ = slice.Replace(values)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+
+	cmd.LocalFlags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetFlags(child)
+	}
+}
@@ -3,13 +3,17 @@ package cmd
 import (
 	"fmt"
 	"log/slog"
+	"os"
+	"text/template"
 
+	"github.com/dusktreader/gowatch/output"
 	"github.com/dusktreader/gowatch/timer"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	showCmd.PersistentFlags().BoolP("full", "f", false, "Show the full timer")
+	showCmd.PersistentFlags().String("format", "", "Render with a Go template (see README for fields and helpers)")
 	rootCmd.AddCommand(showCmd)
 }
 
@@ -18,6 +22,7 @@ var showCmd = &cobra.Command{
 	Short:	"Show a timer",
 	Long:	"Show a named timer",
 	Args:	cobra.MaximumNArgs(1),
+	ValidArgsFunction:	completeTimerNames(anyNode),
 	Run:	showMain,
 }
 
@@ -25,23 +30,78 @@ func showMain(cmd *cobra.Command, args []string){
 	full, err := cmd.Flags().GetBool("full")
 	MaybeDie(err)
 
-	var name string
-	if len(args) == 0 {
-		name = timer.DEFAULT_TIMER_NAME
-	} else {
-		name = args[0]
-	}
+	format, err := cmd.Flags().GetString("output")
+	MaybeDie(err)
+
+	tmpl := formatTemplate(cmd)
+
+	name := timerName(args)
+
+	exists, err := store.Exists(name)
+	MaybeDie(err)
 
-	cacheDir := timer.GetCacheDir()
+	// A name without a timer of its own may still be the parent of other timers.
+	if !exists {
+		nts, err := timer.Snapshot(store)
+		MaybeDie(err)
 
-	t, err := timer.Load(name, cacheDir, true)
+		subtree := timer.SelectSubtree(nts, name)
+		if len(subtree) == 0 {
+			Die("Timer does not exist: %v", name)
+		}
+		showRollup(cmd, name, subtree, full, format, tmpl)
+		return
+	}
+
+	t, err := store.Load(name, true)
 	MaybeDie(err)
 
-	if full {
+	if tmpl != nil {
+		slog.Debug("Showing templated timer", "Name", name)
+		err = output.WriteTemplate(os.Stdout, tmpl, []output.Record{output.NewRecord(name, t)})
+		MaybeDie(err)
+	} else if format != output.TEXT {
+		slog.Debug("Showing structured timer", "Name", name, "Format", format)
+		err = output.WriteRecord(os.Stdout, format, output.NewRecord(name, t))
+		MaybeDie(err)
+	} else if full {
 		slog.Debug("Showing full timer", "Name", name)
 		fmt.Println(t)
+		for i := range t.Segments {
+			fmt.Printf("  %d: %s\n", i + 1, &t.Segments[i])
+		}
+		for i := range t.Laps {
+			fmt.Printf("  %s\n", t.LapString(i))
+		}
+		if t.IsCountdown() {
+			fmt.Printf("  Target: %s (%s)\n", t.Target, t.RemainingString())
+		}
+	} else if t.IsCountdown() {
+		slog.Debug("Showing countdown timer", "Name", name)
+		fmt.Println(t.RemainingString())
 	} else {
 		slog.Debug("Showing compact timer", "Name", name)
 		fmt.Println(t.ElapsedString())
 	}
 }
+
+func showRollup(cmd *cobra.Command, name string, subtree []*timer.NamedTimer, full bool, format string, tmpl *template.Template) {
+	node := timer.Find(timer.BuildTree(subtree), name)
+	record := output.NewNodeRecord(node)
+
+	if tmpl != nil {
+		slog.Debug("Showing templated rollup", "Name", name)
+		err := output.WriteTemplate(os.Stdout, tmpl, []output.Record{record})
+		MaybeDie(err)
+	} else if format != output.TEXT {
+		slog.Debug("Showing structured rollup", "Name", name, "Format", format)
+		err := output.WriteRecord(os.Stdout, format, record)
+		MaybeDie(err)
+	} else if full {
+		slog.Debug("Showing full rollup", "Name", name)
+		printTree([]*timer.Node{node}, true)
+	} else {
+		slog.Debug("Showing compact rollup", "Name", name)
+		fmt.Println(record.ElapsedString)
+	}
+}
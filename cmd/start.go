@@ -2,12 +2,18 @@ package cmd
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/dusktreader/gowatch/timer"
 	"github.com/spf13/cobra"
 )
 
 func init() {
+	startCmd.PersistentFlags().Duration("for", 0, "Count down from this duration")
+	startCmd.PersistentFlags().StringSliceP("tag", "t", nil, "Tag the timer (repeatable)")
+	startCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the new interval")
+	addMomentFlags(startCmd, "Start")
+	addExclusiveFlag(startCmd)
 	rootCmd.AddCommand(startCmd)
 }
 
@@ -16,28 +22,86 @@ var startCmd = &cobra.Command{
 	Short:	"Start a timer",
 	Long:	"Start a named timer",
 	Args:	cobra.MaximumNArgs(1),
+	ValidArgsFunction:	completeTimerNames(stoppedTimer),
 	Run:	startMain,
 }
 
-func startMain(_ *cobra.Command, args []string){
-	var name string
-	if len(args) == 0 {
-		name = timer.DEFAULT_TIMER_NAME
-	} else {
-		name = args[0]
+// startTimer starts a timer and lets update change it before it is saved. In exclusive mode, every other
+// running timer is stopped at the same moment, and those timers are returned; the caller must hold the store
+// lock from lockStore.
+func startTimer(name string, np timer.NowProvider, exclusive bool, update func(t *timer.Timer) error) []*timer.NamedTimer {
+	var started time.Time
+	prepare := func(t *timer.Timer) error {
+		started = t.Current().Start
+		if update == nil {
+			return nil
+		}
+		return update(t)
 	}
 
-	cacheDir := timer.GetCacheDir()
+	if exclusive {
+		slog.Debug("Starting timer exclusively", "Name", name)
+		paused, err := timer.StartExclusive(store, name, prepare, np)
+		MaybeDie(err)
 
-	t, err := timer.Load(name, cacheDir)
+		for _, nt := range paused {
+			recordStop(nt.Name, nt.Ticks.Ended())
+		}
+		recordStart(name, started)
+		slog.Debug("Timer started", "Name", name, "Paused", len(paused))
+		return paused
+	}
+
+	unlock := lockTimer(name)
+	defer unlock()
+
+	t, err := store.Load(name)
 	MaybeDie(err)
 
 	slog.Debug("Starting timer", "Name", name)
-	err = t.Start()
+	err = t.Start(np)
+	MaybeDie(err)
+
+	err = prepare(t)
 	MaybeDie(err)
 
-	err = t.Dump(name, cacheDir)
+	err = store.Dump(name, t)
 	MaybeDie(err)
+	recordStart(name, started)
 
 	slog.Debug("Timer started", "Name", name, "Timer", t)
+	return nil
+}
+
+func startMain(cmd *cobra.Command, args []string){
+	target, err := cmd.Flags().GetDuration("for")
+	MaybeDie(err)
+	if target < 0 {
+		Die("Countdown duration must not be negative: %v", target)
+	}
+
+	tags, err := cmd.Flags().GetStringSlice("tag")
+	MaybeDie(err)
+
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	name := timerName(args)
+	np := momentProvider(cmd)
+
+	exclusive := exclusiveMode(cmd)
+	if exclusive {
+		unlockStore := lockStore()
+		defer unlockStore()
+	}
+
+	paused := startTimer(name, np, exclusive, func(t *timer.Timer) error {
+		if target > 0 {
+			slog.Debug("Setting countdown target", "Name", name, "Target", target)
+			t.Target = target
+		}
+		t.Current().AddNote(note)
+		return t.AddTags(tags...)
+	})
+	reportPaused(paused)
 }
@@ -3,43 +3,96 @@ package cmd
 import (
 	"fmt"
 	"log/slog"
+	"os"
 
 	"github.com/dusktreader/gowatch/timer"
 	"github.com/spf13/cobra"
 )
 
 func init() {
+	stopCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the interval being stopped")
+	stopCmd.PersistentFlags().BoolP("all", "A", false, "Stop every running timer")
+	addMomentFlags(stopCmd, "Stop")
 	rootCmd.AddCommand(stopCmd)
 }
 
 var stopCmd = &cobra.Command{
 	Use:	"stop",
 	Short:	"Stop a timer",
-	Long:	"Stop a named timer",
+	Long:	"Stop a named timer, or every running timer with --all",
 	Args:	cobra.MaximumNArgs(1),
+	ValidArgsFunction:	completeTimerNames(runningTimer),
 	Run:	stopMain,
 }
 
-func stopMain(_ *cobra.Command, args []string){
-	var name string
-	if len(args) == 0 {
-		name = timer.DEFAULT_TIMER_NAME
-	} else {
-		name = args[0]
+// stopTimer stops a running timer, returning it so that the caller can report the elapsed time. With
+// ifRunning, a timer that is no longer running once it is locked is left alone and nil is returned.
+func stopTimer(name string, note string, np timer.NowProvider, ifRunning bool) *timer.Timer {
+	unlock := lockTimer(name)
+	defer unlock()
+
+	t, err := store.Load(name)
+	MaybeDie(err)
+
+	if t.IsRunning() {
+		t.Current().AddNote(note)
+	} else if ifRunning {
+		slog.Debug("Timer is no longer running", "Name", name)
+		return nil
 	}
 
-	cacheDir := timer.GetCacheDir()
+	slog.Debug("Stopping timer", "Name", name)
+	err = t.Stop(np)
+	MaybeDie(err)
 
-	t, err := timer.Load(name, cacheDir)
+	err = store.Dump(name, t)
 	MaybeDie(err)
+	recordStop(name, t.Ended())
 
-	slog.Debug("Stopping timer", "Name", name)
-	err = t.Stop()
+	slog.Debug("Timer stopped", "Name", name, "Timer", t)
+	return t
+}
+
+func stopMain(cmd *cobra.Command, args []string){
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	all, err := cmd.Flags().GetBool("all")
 	MaybeDie(err)
 
-	err = t.Dump(name, cacheDir)
+	np := momentProvider(cmd)
+
+	if !all {
+		t := stopTimer(timerName(args), note, np, false)
+		fmt.Println(t.ElapsedString())
+		return
+	}
+
+	if len(args) > 0 {
+		Die("The --all flag can't be combined with a timer name")
+	}
+
+	// The store lock keeps exclusive starts out while the timers are stopped. A timer stopped by another
+	// command since the scan is skipped.
+	unlockStore := lockStore()
+	defer unlockStore()
+
+	nts, err := store.LoadAll()
 	MaybeDie(err)
 
-	slog.Debug("Timer started", "Name", name, "Timer", t)
-	fmt.Println(t.ElapsedString())
+	stopped := 0
+	for _, nt := range nts {
+		if !nt.Ticks.IsRunning() {
+			continue
+		}
+		t := stopTimer(nt.Name, note, np, true)
+		if t == nil {
+			continue
+		}
+		fmt.Printf("%s: %s\n", nt.Name, t.ElapsedString())
+		stopped++
+	}
+	if stopped == 0 {
+		fmt.Fprintln(os.Stderr, "No running timers")
+	}
 }
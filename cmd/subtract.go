@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	subtractCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the adjustment")
+	rootCmd.AddCommand(subtractCmd)
+}
+
+var subtractCmd = &cobra.Command{
+	Use:	"subtract [name] <duration>",
+	Short:	"Subtract time from a timer",
+	Long:	"Subtract a duration like 15m from a named timer",
+	Args:	cobra.RangeArgs(1, 2),
+	Run:	subtractMain,
+}
+
+func subtractMain(cmd *cobra.Command, args []string){
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	name, d := adjustmentArgs(cmd, args)
+
+	unlock := lockTimer(name)
+	defer unlock()
+
+	t, err := store.Load(name, true)
+	MaybeDie(err)
+
+	slog.Debug("Subtracting time", "Name", name, "Duration", d)
+	err = t.Adjust(-d, note)
+	MaybeDie(err)
+
+	err = store.Dump(name, t)
+	MaybeDie(err)
+
+	slog.Debug("Time subtracted", "Name", name, "Timer", t)
+	fmt.Println(t.ElapsedString())
+}
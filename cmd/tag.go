@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	tagCmd.PersistentFlags().BoolP("remove", "r", false, "Remove the tags instead of adding them")
+	rootCmd.AddCommand(tagCmd)
+}
+
+var tagCmd = &cobra.Command{
+	Use:	"tag <name> [tags...]",
+	Short:	"Tag a timer",
+	Long:	"Add tags to a named timer, remove them with --remove, or show its tags when none are given",
+	Args:	cobra.MinimumNArgs(1),
+	Run:	tagMain,
+}
+
+func tagMain(cmd *cobra.Command, args []string){
+	remove, err := cmd.Flags().GetBool("remove")
+	MaybeDie(err)
+
+	name := args[0]
+	tags := args[1:]
+
+	if len(tags) > 0 {
+		unlock := lockTimer(name)
+		defer unlock()
+	}
+
+	t, err := store.Load(name, true)
+	MaybeDie(err)
+
+	if len(tags) > 0 {
+		if remove {
+			slog.Debug("Removing tags", "Name", name, "Tags", tags)
+			t.RemoveTags(tags...)
+		} else {
+			slog.Debug("Adding tags", "Name", name, "Tags", tags)
+			err = t.AddTags(tags...)
+			MaybeDie(err)
+		}
+
+		err = store.Dump(name, t)
+		MaybeDie(err)
+	}
+
+	fmt.Println(strings.Join(t.Tags, " "))
+}
@@ -9,6 +9,9 @@ import (
 )
 
 func init() {
+	toggleCmd.PersistentFlags().StringP("note", "m", "", "Attach a note to the interval being started or stopped")
+	addMomentFlags(toggleCmd, "Toggle")
+	addExclusiveFlag(toggleCmd)
 	rootCmd.AddCommand(toggleCmd)
 }
 
@@ -17,30 +20,59 @@ var toggleCmd = &cobra.Command{
 	Short:	"Toggle a timer",
 	Long:	"Toggle a named timer",
 	Args:	cobra.MaximumNArgs(1),
+	ValidArgsFunction:	completeTimerNames(anyTimer),
 	Run:	toggleMain,
 }
 
-func toggleMain(_ *cobra.Command, args []string){
-	var name string
-	if len(args) == 0 {
-		name = timer.DEFAULT_TIMER_NAME
-	} else {
-		name = args[0]
+func toggleMain(cmd *cobra.Command, args []string){
+	note, err := cmd.Flags().GetString("note")
+	MaybeDie(err)
+
+	name := timerName(args)
+	np := momentProvider(cmd)
+
+	// Starting exclusively locks the other running timers too, so it can't happen under this timer's lock. The
+	// store lock keeps other exclusive starts out while the timer is checked.
+	if exclusiveMode(cmd) {
+		unlockStore := lockStore()
+		defer unlockStore()
+
+		t, err := store.Load(name)
+		MaybeDie(err)
+		if !t.IsRunning() {
+			paused := startTimer(name, np, true, func(t *timer.Timer) error {
+				t.Current().AddNote(note)
+				return nil
+			})
+			reportPaused(paused)
+			return
+		}
 	}
 
-	cacheDir := timer.GetCacheDir()
+	unlock := lockTimer(name)
+	defer unlock()
 
-	t, err := timer.Load(name, cacheDir)
+	t, err := store.Load(name)
 	MaybeDie(err)
 
 	slog.Debug("Toggling timer", "Name", name)
-	wasStopped := t.Toggle()
+	wasStopped, err := t.Toggle(np)
+	MaybeDie(err)
+
+	if wasStopped {
+		t.Segments[len(t.Segments) - 1].AddNote(note)
+	} else {
+		t.Current().AddNote(note)
+	}
 	slog.Debug("Timer toggled", "Name", name, "Timer", t)
 
-	err = t.Dump(name, cacheDir)
+	err = store.Dump(name, t)
 	MaybeDie(err)
 
 	if wasStopped {
+		recordStop(name, t.Ended())
 		fmt.Println(t.ElapsedString())
+	} else {
+		recordStart(name, t.Current().Start)
 	}
 }
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dusktreader/gowatch/dashboard"
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func init() {
+	uiCmd.PersistentFlags().DurationP("interval", "i", time.Second, "How often to redraw the dashboard")
+	addExclusiveFlag(uiCmd)
+	rootCmd.AddCommand(uiCmd)
+}
+
+var uiCmd = &cobra.Command{
+	Use:	"ui",
+	Short:	"Open the dashboard",
+	Long:	"Open an interactive dashboard for starting, stopping and managing all timers",
+	Args:	cobra.NoArgs,
+	Run:	uiMain,
+}
+
+func uiMain(cmd *cobra.Command, _ []string){
+	interval, err := cmd.Flags().GetDuration("interval")
+	MaybeDie(err)
+	if interval <= 0 {
+		Die("Refresh interval must be positive: %v", interval)
+	}
+	exclusive := exclusiveMode(cmd)
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !isTerminal(os.Stdout) {
+		Die("The dashboard needs an interactive terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	MaybeDie(err)
+
+	// Use the alternate screen and hide the cursor while the dashboard is open.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	restore := func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		_ = term.Restore(fd, state)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	d := dashboard.New(store, timer.RealNowProvider{})
+	d.Exclusive = exclusive
+	slog.Debug("Opening dashboard", "Interval", interval, "Exclusive", d.Exclusive)
+	err = d.Run(ctx, os.Stdin, os.Stdout, ticker.C)
+	restore()
+	MaybeDie(err)
+}
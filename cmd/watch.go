@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dusktreader/gowatch/output"
+	"github.com/dusktreader/gowatch/timer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	watchCmd.PersistentFlags().DurationP("interval", "i", time.Second, "How often to redraw the timers")
+	watchCmd.PersistentFlags().BoolP("all", "A", false, "Watch all timers")
+	rootCmd.AddCommand(watchCmd)
+}
+
+var watchCmd = &cobra.Command{
+	Use:	"watch",
+	Short:	"Watch timers",
+	Long:	"Continuously redraw one or more named timers until interrupted",
+	Run:	watchMain,
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode() & os.ModeCharDevice != 0
+}
+
+func watchMain(cmd *cobra.Command, args []string){
+	interval, err := cmd.Flags().GetDuration("interval")
+	MaybeDie(err)
+	if interval <= 0 {
+		Die("Refresh interval must be positive: %v", interval)
+	}
+
+	all, err := cmd.Flags().GetBool("all")
+	MaybeDie(err)
+
+	names := args
+	if len(names) == 0 {
+		names = []string{cfg.DefaultTimerName()}
+	}
+
+	load := func() ([]*timer.NamedTimer, error) {
+		if all {
+			return timer.Snapshot(store)
+		}
+
+		// Several timers are read, so take the store lock like Snapshot does.
+		lock, err := store.LockStore()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = lock.Unlock() }()
+
+		nts := make([]*timer.NamedTimer, 0, len(names))
+		for _, name := range names {
+			t, err := store.Load(name, true)
+			if err != nil {
+				return nil, err
+			}
+			nts = append(nts, &timer.NamedTimer{Name: name, Ticks: t})
+		}
+		return nts, nil
+	}
+
+	precision := time.Second
+	if interval < time.Second {
+		precision = 10 * time.Millisecond
+	}
+
+	watcher := &output.Watcher{
+		Out: os.Stdout,
+		TTY: isTerminal(os.Stdout),
+		Precision: precision,
+		Load: load,
+		Now: timer.RealNowProvider{},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	slog.Debug("Watching timers", "Names", names, "All", all, "Interval", interval, "TTY", watcher.TTY)
+	err = watcher.Run(ctx, ticker.C)
+	MaybeDie(err)
+}
@@ -0,0 +1,428 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dusktreader/gowatch/output"
+	"github.com/dusktreader/gowatch/timer"
+	"gopkg.in/yaml.v3"
+)
+
+// FILE_NAME is the name of the config file in timer.GetConfigDir().
+const FILE_NAME = "config.yaml"
+
+// ENV_PREFIX starts the environment variables that override config keys, like GOWATCH_CACHE_DIR.
+const ENV_PREFIX = "GOWATCH_"
+
+const (
+	DEFAULT_TIMER	= "default_timer"
+	CACHE_DIR		= "cache_dir"
+	STORE			= "store"
+	OUTPUT			= "output"
+	PRECISION		= "precision"
+	EXCLUSIVE		= "exclusive"
+)
+
+var Keys = []string{DEFAULT_TIMER, CACHE_DIR, STORE, OUTPUT, PRECISION, EXCLUSIVE}
+
+// COMMANDS_PREFIX starts the keys of per-command default flags, like commands.report.by.
+const COMMANDS_PREFIX = "commands."
+
+// ALIASES_PREFIX and MACROS_PREFIX start the keys of user-defined commands, like aliases.standup.
+const (
+	ALIASES_PREFIX	= "aliases."
+	MACROS_PREFIX	= "macros."
+)
+
+const (
+	FROM_DEFAULT		= "default"
+	FROM_FILE			= "file"
+	FROM_ENVIRONMENT	= "environment"
+)
+
+// Config is the content of the config file. Empty fields fall back to their defaults.
+type Config struct {
+	DefaultTimer	string							`yaml:"default_timer,omitempty"`
+	CacheDir		string							`yaml:"cache_dir,omitempty"`
+	Store			string							`yaml:"store,omitempty"`
+	Output			string							`yaml:"output,omitempty"`
+	Precision		string							`yaml:"precision,omitempty"`
+	Exclusive		string							`yaml:"exclusive,omitempty"`
+	Commands		map[string]map[string]string	`yaml:"commands,omitempty"`
+	Aliases			map[string]string				`yaml:"aliases,omitempty"`
+	Macros			map[string]string				`yaml:"macros,omitempty"`
+}
+
+// Entry is one effective setting and where its value came from.
+type Entry struct {
+	Key		string
+	Value	string
+	Source	string
+}
+
+// Path returns the location of the config file, which GOWATCH_CONFIG overrides.
+func Path() string {
+	path := os.Getenv(ENV_PREFIX + "CONFIG")
+	if path != "" {
+		return path
+	}
+	return filepath.Join(timer.GetConfigDir(), FILE_NAME)
+}
+
+// Load reads the config file at path. A missing file is an empty config.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read config file: %v", err)
+	}
+
+	err = yaml.Unmarshal(data, c)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse config file %v: %v", path, err)
+	}
+	return c, nil
+}
+
+func (c *Config) Save(path string) error {
+	buffer := new(bytes.Buffer)
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(c)
+	if err != nil {
+		return fmt.Errorf("Couldn't encode config: %v", err)
+	}
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("Couldn't encode config: %v", err)
+	}
+
+	err = timer.EnsureDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, buffer.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("Couldn't write config file: %v", err)
+	}
+	return nil
+}
+
+func defaultValue(key string) string {
+	switch key {
+	case DEFAULT_TIMER:
+		return timer.DEFAULT_TIMER_NAME
+	case CACHE_DIR:
+		return timer.GetCacheDir()
+	case STORE:
+		return "file"
+	case OUTPUT:
+		return output.TEXT
+	case PRECISION:
+		return time.Millisecond.String()
+	case EXCLUSIVE:
+		return "false"
+	}
+	return ""
+}
+
+func (c *Config) field(key string) *string {
+	switch key {
+	case DEFAULT_TIMER:
+		return &c.DefaultTimer
+	case CACHE_DIR:
+		return &c.CacheDir
+	case STORE:
+		return &c.Store
+	case OUTPUT:
+		return &c.Output
+	case PRECISION:
+		return &c.Precision
+	case EXCLUSIVE:
+		return &c.Exclusive
+	}
+	return nil
+}
+
+// splitCommandKey splits a key like commands.report.by into the command and flag names.
+func splitCommandKey(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, COMMANDS_PREFIX)
+	if !ok {
+		return "", "", false
+	}
+	command, flag, ok := strings.Cut(rest, ".")
+	if !ok || command == "" || flag == "" || strings.Contains(flag, ".") {
+		return "", "", false
+	}
+	return command, flag, true
+}
+
+// shortcuts returns the map holding the alias or macro named by a key like aliases.standup, creating it if
+// needed.
+func (c *Config) shortcuts(key string) (map[string]string, string, bool) {
+	if name, ok := strings.CutPrefix(key, ALIASES_PREFIX); ok {
+		if c.Aliases == nil {
+			c.Aliases = map[string]string{}
+		}
+		return c.Aliases, name, true
+	}
+	if name, ok := strings.CutPrefix(key, MACROS_PREFIX); ok {
+		if c.Macros == nil {
+			c.Macros = map[string]string{}
+		}
+		return c.Macros, name, true
+	}
+	return nil, "", false
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf(
+		"Unknown config key %v: expected one of %v, %s<command>.<flag>, %s<name> or %s<name>",
+		key,
+		Keys,
+		COMMANDS_PREFIX,
+		ALIASES_PREFIX,
+		MACROS_PREFIX,
+	)
+}
+
+// CheckShortcutName validates the name of an alias or macro, which becomes a subcommand.
+func CheckShortcutName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, ". \t\n") {
+		return fmt.Errorf("Invalid alias or macro name %q: names must not start with - or contain dots or whitespace", name)
+	}
+	return nil
+}
+
+// checkShortcut validates an alias or macro definition. Aliases expand to a single command.
+func checkShortcut(key string, name string, value string) error {
+	err := CheckShortcutName(name)
+	if err != nil {
+		return err
+	}
+	steps, err := SplitCommands(value)
+	if err != nil {
+		return fmt.Errorf("Invalid definition for %v: %v", key, err)
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("Invalid definition for %v: it runs no commands", key)
+	}
+	if strings.HasPrefix(key, ALIASES_PREFIX) && len(steps) > 1 {
+		return fmt.Errorf("Invalid definition for %v: an alias runs a single command; use a macro for more", key)
+	}
+	return nil
+}
+
+// Get returns the value set in the file for a key, ignoring the environment and defaults.
+func (c *Config) Get(key string) (string, error) {
+	if command, flag, ok := splitCommandKey(key); ok {
+		return c.Commands[command][flag], nil
+	}
+	if shortcuts, name, ok := c.shortcuts(key); ok {
+		return shortcuts[name], nil
+	}
+	field := c.field(key)
+	if field == nil {
+		return "", unknownKey(key)
+	}
+	return *field, nil
+}
+
+// Set changes the value of a key in the file. An empty value removes the key.
+func (c *Config) Set(key string, value string) error {
+	if command, flag, ok := splitCommandKey(key); ok {
+		if value == "" {
+			delete(c.Commands[command], flag)
+			if len(c.Commands[command]) == 0 {
+				delete(c.Commands, command)
+			}
+			return nil
+		}
+		if c.Commands == nil {
+			c.Commands = map[string]map[string]string{}
+		}
+		if c.Commands[command] == nil {
+			c.Commands[command] = map[string]string{}
+		}
+		c.Commands[command][flag] = value
+		return nil
+	}
+	if shortcuts, name, ok := c.shortcuts(key); ok {
+		if value == "" {
+			delete(shortcuts, name)
+			return nil
+		}
+		err := checkShortcut(key, name, value)
+		if err != nil {
+			return err
+		}
+		shortcuts[name] = value
+		return nil
+	}
+
+	field := c.field(key)
+	if field == nil {
+		return unknownKey(key)
+	}
+	if value != "" {
+		err := check(key, value)
+		if err != nil {
+			return err
+		}
+	}
+	*field = value
+	return nil
+}
+
+// Value returns the effective value of a key: from the environment, then the file, then the default.
+func (c *Config) Value(key string) (string, string) {
+	value := os.Getenv(ENV_PREFIX + strings.ToUpper(key))
+	if value != "" {
+		return value, FROM_ENVIRONMENT
+	}
+	field := c.field(key)
+	if field != nil && *field != "" {
+		return *field, FROM_FILE
+	}
+	return defaultValue(key), FROM_DEFAULT
+}
+
+func check(key string, value string) error {
+	switch key {
+	case DEFAULT_TIMER:
+		return timer.CheckName(value)
+	case STORE:
+		if !slices.Contains(timer.StoreKinds(), value) {
+			return fmt.Errorf("Unknown store kind %v: expected one of %v", value, timer.StoreKinds())
+		}
+	case OUTPUT:
+		return output.CheckFormat(value)
+	case PRECISION:
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("Invalid precision %q: expected a positive duration like 1s or 1ms", value)
+		}
+	case EXCLUSIVE:
+		_, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value %q for exclusive: expected true or false", value)
+		}
+	}
+	return nil
+}
+
+// Check validates the effective value of every key.
+func (c *Config) Check() error {
+	for _, key := range Keys {
+		value, source := c.Value(key)
+		err := check(key, value)
+		if err != nil {
+			return fmt.Errorf("%v (%v set in the %v)", err, key, source)
+		}
+	}
+	for name, value := range c.Aliases {
+		err := checkShortcut(ALIASES_PREFIX + name, name, value)
+		if err != nil {
+			return err
+		}
+	}
+	for name, value := range c.Macros {
+		err := checkShortcut(MACROS_PREFIX + name, name, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) DefaultTimerName() string {
+	value, _ := c.Value(DEFAULT_TIMER)
+	return value
+}
+
+// StorageDir returns the storage location, expanding a leading ~ to the home directory.
+func (c *Config) StorageDir() string {
+	value, _ := c.Value(CACHE_DIR)
+	if rest, ok := strings.CutPrefix(value, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return value
+}
+
+func (c *Config) StoreKind() string {
+	value, _ := c.Value(STORE)
+	return value
+}
+
+func (c *Config) OutputFormat() string {
+	value, _ := c.Value(OUTPUT)
+	return value
+}
+
+// DisplayPrecision returns the duration display precision, falling back to the default if it is invalid.
+func (c *Config) DisplayPrecision() time.Duration {
+	value, _ := c.Value(PRECISION)
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return time.Millisecond
+	}
+	return d
+}
+
+// ExclusiveMode reports whether starting a timer should stop every other running timer.
+func (c *Config) ExclusiveMode() bool {
+	value, _ := c.Value(EXCLUSIVE)
+	exclusive, _ := strconv.ParseBool(value)
+	return exclusive
+}
+
+// CommandFlags returns the default flags configured for a command.
+func (c *Config) CommandFlags(command string) map[string]string {
+	return c.Commands[command]
+}
+
+// Entries lists the effective value of every key, followed by the per-command flags, aliases and macros in
+// the file.
+func (c *Config) Entries() []Entry {
+	entries := make([]Entry, 0, len(Keys))
+	for _, key := range Keys {
+		value, source := c.Value(key)
+		entries = append(entries, Entry{Key: key, Value: value, Source: source})
+	}
+
+	commandEntries := []Entry{}
+	for command, flags := range c.Commands {
+		for flag, value := range flags {
+			commandEntries = append(
+				commandEntries,
+				Entry{Key: COMMANDS_PREFIX + command + "." + flag, Value: value, Source: FROM_FILE},
+			)
+		}
+	}
+	for name, value := range c.Aliases {
+		commandEntries = append(commandEntries, Entry{Key: ALIASES_PREFIX + name, Value: value, Source: FROM_FILE})
+	}
+	for name, value := range c.Macros {
+		commandEntries = append(commandEntries, Entry{Key: MACROS_PREFIX + name, Value: value, Source: FROM_FILE})
+	}
+	sort.Slice(commandEntries, func(i, j int) bool {
+		return commandEntries[i].Key < commandEntries[j].Key
+	})
+	return append(entries, commandEntries...)
+}
@@ -0,0 +1,249 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/config"
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestLoad_NoFile(t *testing.T) {
+	c, err := config.Load(filepath.Join(t.TempDir(), config.FILE_NAME))
+	if err != nil {
+		t.Fatalf("Load returned an error for a missing file: %v", err)
+	}
+	if !reflect.DeepEqual(&config.Config{}, c) {
+		t.Errorf("Load didn't return an empty config: got %v", c)
+	}
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), config.FILE_NAME)
+	err := os.WriteFile(path, []byte("precision: [1s"), 0644)
+	if err != nil {
+		t.Fatalf("Couldn't write config file: %v", err)
+	}
+
+	_, err = config.Load(path)
+	if err == nil {
+		t.Errorf("Load didn't return an error for an invalid file")
+	}
+}
+
+func TestSave_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", config.FILE_NAME)
+
+	c := &config.Config{}
+	settings := map[string]string{
+		config.DEFAULT_TIMER: "work",
+		config.PRECISION: "1s",
+		"commands.report.by": "week",
+	}
+	for key, value := range settings {
+		err := c.Set(key, value)
+		if err != nil {
+			t.Fatalf("Set returned an error for %v: %v", key, err)
+		}
+	}
+
+	err := c.Save(path)
+	if err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	got, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+	for key, want := range settings {
+		value, err := got.Get(key)
+		if err != nil || value != want {
+			t.Errorf("Config didn't round trip %v: wanted %v, got %v (%v)", key, want, value, err)
+		}
+	}
+}
+
+func TestSet_Invalid(t *testing.T) {
+	c := &config.Config{}
+	invalid := map[string]string{
+		config.DEFAULT_TIMER: "a//b",
+		config.STORE: "cloud",
+		config.OUTPUT: "xml",
+		config.PRECISION: "-1s",
+		config.EXCLUSIVE: "sometimes",
+		"nonsense": "1",
+		"commands.report": "week",
+	}
+	for key, value := range invalid {
+		err := c.Set(key, value)
+		if err == nil {
+			t.Errorf("Set didn't reject %v = %v", key, value)
+		}
+	}
+}
+
+func TestSet_Remove(t *testing.T) {
+	c := &config.Config{}
+	_ = c.Set(config.OUTPUT, "json")
+	_ = c.Set("commands.list.full", "true")
+
+	_ = c.Set(config.OUTPUT, "")
+	_ = c.Set("commands.list.full", "")
+	if !reflect.DeepEqual(&config.Config{Commands: map[string]map[string]string{}}, c) {
+		t.Errorf("Set didn't remove the settings: got %v", c)
+	}
+}
+
+func TestValue_Precedence(t *testing.T) {
+	c := &config.Config{}
+
+	value, source := c.Value(config.DEFAULT_TIMER)
+	if value != timer.DEFAULT_TIMER_NAME || source != config.FROM_DEFAULT {
+		t.Errorf("Value didn't fall back to the default: got %v from %v", value, source)
+	}
+
+	_ = c.Set(config.DEFAULT_TIMER, "work")
+	value, source = c.Value(config.DEFAULT_TIMER)
+	if value != "work" || source != config.FROM_FILE {
+		t.Errorf("Value didn't use the file: got %v from %v", value, source)
+	}
+
+	t.Setenv("GOWATCH_DEFAULT_TIMER", "play")
+	value, source = c.Value(config.DEFAULT_TIMER)
+	if value != "play" || source != config.FROM_ENVIRONMENT {
+		t.Errorf("Value didn't prefer the environment: got %v from %v", value, source)
+	}
+	if c.DefaultTimerName() != "play" {
+		t.Errorf("DefaultTimerName didn't use the effective value: got %v", c.DefaultTimerName())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := &config.Config{}
+	err := c.Check()
+	if err != nil {
+		t.Errorf("Check rejected the defaults: %v", err)
+	}
+
+	t.Setenv("GOWATCH_PRECISION", "fast")
+	err = c.Check()
+	if err == nil {
+		t.Errorf("Check didn't reject an invalid environment variable")
+	}
+	if c.DisplayPrecision() != time.Millisecond {
+		t.Errorf("DisplayPrecision didn't fall back to the default: got %v", c.DisplayPrecision())
+	}
+}
+
+func TestExclusiveMode(t *testing.T) {
+	c := &config.Config{}
+	if c.ExclusiveMode() {
+		t.Errorf("ExclusiveMode wasn't off by default")
+	}
+
+	_ = c.Set(config.EXCLUSIVE, "true")
+	if !c.ExclusiveMode() {
+		t.Errorf("ExclusiveMode didn't use the file")
+	}
+
+	t.Setenv("GOWATCH_EXCLUSIVE", "false")
+	if c.ExclusiveMode() {
+		t.Errorf("ExclusiveMode didn't prefer the environment")
+	}
+}
+
+func TestStorageDir(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("Couldn't find home dir: %v", err)
+	}
+
+	c := &config.Config{CacheDir: "~/timers"}
+	want := filepath.Join(home, "timers")
+	if c.StorageDir() != want {
+		t.Errorf("StorageDir didn't expand the home dir: wanted %v, got %v", want, c.StorageDir())
+	}
+}
+
+func TestEntries(t *testing.T) {
+	c := &config.Config{}
+	_ = c.Set("commands.report.by", "week")
+	_ = c.Set("commands.list.full", "true")
+
+	entries := c.Entries()
+	if len(entries) != len(config.Keys) + 2 {
+		t.Fatalf("Entries didn't list every setting: got %v", entries)
+	}
+	want := config.Entry{Key: "commands.list.full", Value: "true", Source: config.FROM_FILE}
+	if entries[len(config.Keys)] != want {
+		t.Errorf("Entries didn't sort the command flags: wanted %v, got %v", want, entries[len(config.Keys)])
+	}
+}
+
+func TestSetShortcuts(t *testing.T) {
+	c := &config.Config{}
+
+	err := c.Set("aliases.standup", "start standup --tag meeting")
+	if err != nil {
+		t.Errorf("Set rejected a valid alias: %v", err)
+	}
+	err = c.Set("macros.meeting", "stop --all; start standup --tag meeting")
+	if err != nil {
+		t.Errorf("Set rejected a valid macro: %v", err)
+	}
+	if c.Aliases["standup"] != "start standup --tag meeting" || len(c.Macros) != 1 {
+		t.Errorf("Set didn't store the shortcuts: got %v and %v", c.Aliases, c.Macros)
+	}
+
+	invalid := map[string]string{
+		"aliases.meeting": "stop --all; start standup",
+		"aliases.bad name": "list",
+		"aliases.-x": "list",
+		"macros.broken": `start "standup`,
+		"macros.empty": " ; ",
+	}
+	for key, value := range invalid {
+		err = c.Set(key, value)
+		if err == nil {
+			t.Errorf("Set didn't reject %v = %v", key, value)
+		}
+	}
+
+	c.Macros["broken"] = "start 'standup"
+	err = c.Check()
+	if err == nil {
+		t.Errorf("Check didn't reject a broken macro in the file")
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	cases := map[string][][]string{
+		"stop --all; start standup --tag meeting": {{"stop", "--all"}, {"start", "standup", "--tag", "meeting"}},
+		`gowatch note 'it''s done; really'`: {{"note", "its done; really"}},
+		`start "team sync" -m "said \"hi\""`: {{"start", "team sync", "-m", `said "hi"`}},
+		`add x 1h\;`: {{"add", "x", "1h;"}},
+		`note ""`: {{"note", ""}},
+		";;": {},
+	}
+	for text, want := range cases {
+		got, err := config.SplitCommands(text)
+		if err != nil {
+			t.Errorf("SplitCommands returned an error for %q: %v", text, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("SplitCommands didn't split %q: wanted %q, got %q", text, want, got)
+		}
+	}
+
+	for _, text := range []string{`start "unclosed`, "start 'unclosed", `start trailing\`} {
+		_, err := config.SplitCommands(text)
+		if err == nil {
+			t.Errorf("SplitCommands didn't reject %q", text)
+		}
+	}
+}
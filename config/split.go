@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+// SplitCommands splits a line like `stop --all; start standup --tag meeting` into commands and their
+// arguments the way a shell would. Single quotes keep their text as is, double quotes and bare words allow
+// backslash escapes, and unquoted semicolons separate the commands. A leading "gowatch" is dropped.
+func SplitCommands(text string) ([][]string, error) {
+	commands := [][]string{}
+	args := []string{}
+	word := new(strings.Builder)
+	inWord := false
+	var quote rune
+	escaped := false
+
+	endWord := func() {
+		if inWord {
+			args = append(args, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(args) > 0 && args[0] == timer.APP_NAME {
+			args = args[1:]
+		}
+		if len(args) > 0 {
+			commands = append(commands, args)
+		}
+		args = []string{}
+	}
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			inWord = true
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			inWord = true
+			quote = r
+		case r == ';':
+			endCommand()
+		case r == ' ' || r == '\t' || r == '\n':
+			endWord()
+		default:
+			inWord = true
+			word.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("Unfinished escape at the end of %q", text)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("Unclosed %c quote in %q", quote, text)
+	}
+	endCommand()
+	return commands, nil
+}
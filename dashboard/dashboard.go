@@ -0,0 +1,456 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+const (
+	KEY_UP		= "up"
+	KEY_DOWN	= "down"
+	KEY_ENTER	= "enter"
+	KEY_ESCAPE	= "escape"
+	KEY_BACK	= "backspace"
+	KEY_QUIT	= "ctrl-c"
+)
+
+type mode int
+
+const (
+	browsing mode = iota
+	naming
+	confirming
+)
+
+const help = "j/k move  s start  p stop  space toggle  r reset  n new  d delete  q quit"
+
+// Dashboard is an interactive view of every timer in a store. Every action goes through the store with the
+// timer locked, exactly like the CLI commands, so the dashboard and the CLI can be used side by side. With
+// Exclusive set, starting a timer stops every other running timer, as in the CLI's exclusive mode.
+type Dashboard struct {
+	Store		timer.Store
+	Now			timer.NowProvider
+	Exclusive	bool
+
+	timers	[]*timer.NamedTimer
+	cursor	int
+	mode	mode
+	input	string
+	message	string
+	done	bool
+}
+
+func New(store timer.Store, nowProvider timer.NowProvider) *Dashboard {
+	return &Dashboard{
+		Store: store,
+		Now: nowProvider,
+	}
+}
+
+func (d *Dashboard) Done() bool {
+	return d.done
+}
+
+func (d *Dashboard) Refresh() error {
+	nts, err := timer.Snapshot(d.Store)
+	if err != nil {
+		return err
+	}
+	d.timers = nts
+	d.cursor = max(0, min(d.cursor, len(d.timers) - 1))
+	return nil
+}
+
+func (d *Dashboard) selected() string {
+	if len(d.timers) == 0 {
+		return ""
+	}
+	return d.timers[d.cursor].Name
+}
+
+func (d *Dashboard) selectName(name string) {
+	for i, nt := range d.timers {
+		if nt.Name == name {
+			d.cursor = i
+			return
+		}
+	}
+}
+
+func (d *Dashboard) update(name string, action func(t *timer.Timer) error) error {
+	lock, err := d.Store.Lock(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	t, err := d.Store.Load(name)
+	if err != nil {
+		return err
+	}
+	wasRunning := t.IsRunning()
+
+	err = action(t)
+	if err != nil {
+		return err
+	}
+	err = d.Store.Dump(name, t)
+	if err != nil {
+		return err
+	}
+
+	// Starts and stops are recorded like the CLI does, so that resume and last see them.
+	running := t.IsRunning()
+	return d.Store.UpdateState(func(state *timer.State) {
+		if running && !wasRunning {
+			state.RecordStart(name, t.Current().Start)
+		} else if wasRunning && !running && len(t.Segments) > 0 {
+			state.RecordStop(name, t.Ended())
+		}
+	})
+}
+
+// start starts a timer, returning the timers an exclusive start paused.
+func (d *Dashboard) start(name string) ([]*timer.NamedTimer, error) {
+	if !d.Exclusive {
+		return nil, d.update(name, func(t *timer.Timer) error { return t.Start() })
+	}
+
+	lock, err := d.Store.LockStore()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	return d.startExclusive(name)
+}
+
+// startExclusive starts a timer and stops every other running one. The caller must hold the store lock.
+func (d *Dashboard) startExclusive(name string) ([]*timer.NamedTimer, error) {
+	var started time.Time
+	paused, err := timer.StartExclusive(d.Store, name, func(t *timer.Timer) error {
+		started = t.Current().Start
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Store.UpdateState(func(state *timer.State) {
+		for _, nt := range paused {
+			state.RecordStop(nt.Name, nt.Ticks.Ended())
+		}
+		state.RecordStart(name, started)
+	})
+	return paused, err
+}
+
+// toggle stops a running timer or starts a stopped one, returning the timers an exclusive start paused.
+func (d *Dashboard) toggle(name string) ([]*timer.NamedTimer, error) {
+	if d.Exclusive {
+		// The store lock keeps other exclusive starts out while the timer is checked.
+		lock, err := d.Store.LockStore()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = lock.Unlock() }()
+
+		t, err := d.Store.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		if !t.IsRunning() {
+			return d.startExclusive(name)
+		}
+	}
+
+	return nil, d.update(name, func(t *timer.Timer) error {
+		_, err := t.Toggle()
+		return err
+	})
+}
+
+func (d *Dashboard) remove(name string) error {
+	lock, err := d.Store.Lock(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	err = d.Store.Clear(name)
+	if err != nil {
+		return err
+	}
+	return d.Store.UpdateState(func(state *timer.State) {
+		state.Forget(name)
+	})
+}
+
+// HandleKey applies a single keystroke. Printable keys are passed as themselves and special keys as one of
+// the KEY_* names. Errors from timer operations are shown on the dashboard rather than returned.
+func (d *Dashboard) HandleKey(key string) error {
+	if key == KEY_QUIT {
+		d.done = true
+		return nil
+	}
+
+	switch d.mode {
+	case naming:
+		d.handleNaming(key)
+	case confirming:
+		d.handleConfirming(key)
+	default:
+		d.handleBrowsing(key)
+	}
+	return d.Refresh()
+}
+
+func (d *Dashboard) report(verb string, name string, err error) {
+	if err != nil {
+		slog.Debug("Dashboard action failed", "Action", verb, "Name", name, "error", err)
+		d.message = fmt.Sprintf("Couldn't %s %s: %v", verb, name, err)
+	} else {
+		d.message = ""
+	}
+}
+
+// reportPaused shows which timers an exclusive start stopped.
+func (d *Dashboard) reportPaused(paused []*timer.NamedTimer) {
+	if len(paused) == 0 {
+		return
+	}
+	names := make([]string, 0, len(paused))
+	for _, nt := range paused {
+		names = append(names, fmt.Sprintf("%s (%s)", nt.Name, nt.Ticks.ElapsedString()))
+	}
+	d.message = "Paused " + strings.Join(names, ", ")
+}
+
+func (d *Dashboard) handleBrowsing(key string) {
+	name := d.selected()
+	switch key {
+	case "q":
+		d.done = true
+	case "j", KEY_DOWN:
+		d.cursor = min(d.cursor + 1, len(d.timers) - 1)
+	case "k", KEY_UP:
+		d.cursor = max(d.cursor - 1, 0)
+	case "n":
+		d.mode = naming
+		d.input = ""
+		d.message = ""
+	case "s":
+		if name != "" {
+			paused, err := d.start(name)
+			d.report("start", name, err)
+			d.reportPaused(paused)
+		}
+	case "p":
+		if name != "" {
+			d.report("stop", name, d.update(name, func(t *timer.Timer) error { return t.Stop() }))
+		}
+	case " ", "t":
+		if name != "" {
+			paused, err := d.toggle(name)
+			d.report("toggle", name, err)
+			d.reportPaused(paused)
+		}
+	case "r":
+		if name != "" {
+			d.report("reset", name, d.update(name, func(t *timer.Timer) error {
+				t.Reset()
+				return nil
+			}))
+		}
+	case "d":
+		if name != "" {
+			d.mode = confirming
+			d.message = ""
+		}
+	}
+}
+
+func (d *Dashboard) handleNaming(key string) {
+	switch key {
+	case KEY_ESCAPE:
+		d.mode = browsing
+	case KEY_BACK:
+		if len(d.input) > 0 {
+			d.input = d.input[:len(d.input) - 1]
+		}
+	case KEY_ENTER:
+		name := strings.TrimSpace(d.input)
+		d.mode = browsing
+		if name == "" {
+			return
+		}
+
+		err := timer.CheckName(name)
+		if err != nil {
+			d.report("create", name, err)
+			return
+		}
+
+		exists, err := d.Store.Exists(name)
+		if err != nil {
+			d.report("create", name, err)
+			return
+		}
+		if !exists {
+			d.report("create", name, d.update(name, func(t *timer.Timer) error { return nil }))
+		}
+		err = d.Refresh()
+		if err != nil {
+			d.report("create", name, err)
+			return
+		}
+		d.selectName(name)
+	default:
+		if len(key) == 1 && key[0] >= ' ' && key[0] <= '~' {
+			d.input += key
+		}
+	}
+}
+
+func (d *Dashboard) handleConfirming(key string) {
+	d.mode = browsing
+	name := d.selected()
+	if key == "y" && name != "" {
+		d.report("delete", name, d.remove(name))
+	}
+}
+
+// Render draws the dashboard as lines separated by CRLF, which is what a terminal in raw mode expects.
+func (d *Dashboard) Render() string {
+	lines := []string{
+		fmt.Sprintf("gowatch: %d timers", len(d.timers)),
+		"",
+	}
+
+	maxWidth := 0
+	for _, nt := range d.timers {
+		maxWidth = max(maxWidth, len(nt.Name))
+	}
+
+	if len(d.timers) == 0 {
+		lines = append(lines, "  No timers found. Press n to create one.")
+	}
+	for i, nt := range d.timers {
+		cursor := " "
+		if i == d.cursor {
+			cursor = ">"
+		}
+
+		state := "stopped"
+		if nt.Ticks.IsRunning() {
+			state = "running"
+		}
+		if nt.Ticks.IsExpired(d.Now) {
+			state = "expired"
+		}
+
+		elapsed := nt.Ticks.Elapsed(d.Now).Round(time.Second)
+		lines = append(lines, fmt.Sprintf("%s %-*s  %12s  %s", cursor, maxWidth, nt.Name, elapsed, state))
+	}
+
+	lines = append(lines, "")
+	switch d.mode {
+	case naming:
+		lines = append(lines, "New timer name: " + d.input + "_")
+	case confirming:
+		lines = append(lines, fmt.Sprintf("Delete %s? (y/n)", d.selected()))
+	default:
+		lines = append(lines, help)
+	}
+	if d.message != "" {
+		lines = append(lines, d.message)
+	}
+
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// decodeKeys splits raw terminal input into keys, recognizing the escape sequences for the arrow keys.
+func decodeKeys(data []byte) []string {
+	keys := make([]string, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch b := data[i]; {
+		case b == 0x1b && i + 2 < len(data) && data[i + 1] == '[':
+			switch data[i + 2] {
+			case 'A':
+				keys = append(keys, KEY_UP)
+			case 'B':
+				keys = append(keys, KEY_DOWN)
+			}
+			i += 2
+		case b == 0x1b:
+			keys = append(keys, KEY_ESCAPE)
+		case b == 0x03:
+			keys = append(keys, KEY_QUIT)
+		case b == '\r' || b == '\n':
+			keys = append(keys, KEY_ENTER)
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, KEY_BACK)
+		default:
+			keys = append(keys, string(b))
+		}
+	}
+	return keys
+}
+
+// Run redraws the dashboard on every tick and every keystroke read from in, until the user quits, the
+// context is cancelled or in is closed. The caller is responsible for putting the terminal into raw mode.
+func (d *Dashboard) Run(ctx context.Context, in io.Reader, out io.Writer, ticks <-chan time.Time) error {
+	keys := make(chan []string)
+	go func() {
+		defer close(keys)
+		buffer := make([]byte, 64)
+		for {
+			n, err := in.Read(buffer)
+			if n > 0 {
+				keys <- decodeKeys(buffer[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	err := d.Refresh()
+	if err != nil {
+		return err
+	}
+
+	for !d.done {
+		_, err = io.WriteString(out, "\x1b[H\x1b[2J" + d.Render())
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticks:
+			err = d.Refresh()
+		case batch, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, key := range batch {
+				err = d.HandleKey(key)
+				if err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
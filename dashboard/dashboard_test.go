@@ -0,0 +1,232 @@
+package dashboard_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/dashboard"
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func freeze(m string) timer.FixedNowProvider {
+	moment, err := time.Parse(time.RFC3339, m)
+	if err != nil {
+		panic("Couldn't parse time: " + m)
+	}
+	return timer.FixedNowProvider{Moment: moment}
+}
+
+func press(t *testing.T, d *dashboard.Dashboard, keys ...string) {
+	for _, key := range keys {
+		err := d.HandleKey(key)
+		if err != nil {
+			t.Fatalf("HandleKey returned an error for %q: %v", key, err)
+		}
+	}
+}
+
+func load(t *testing.T, store timer.Store, name string) *timer.Timer {
+	ticks, err := store.Load(name, true)
+	if err != nil {
+		t.Fatalf("Couldn't load %v: %v", name, err)
+	}
+	return ticks
+}
+
+func TestDashboard_CreateAndToggle(t *testing.T) {
+	store := timer.NewMemoryStore()
+	d := dashboard.New(store, freeze("2025-03-11T10:00:00Z"))
+	err := d.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh returned an error: %v", err)
+	}
+
+	press(t, d, "n", "w", "o", "r", "x", dashboard.KEY_BACK, "k", dashboard.KEY_ENTER)
+	ticks := load(t, store, "work")
+	if ticks.IsRunning() {
+		t.Errorf("Creating a timer started it")
+	}
+
+	press(t, d, " ")
+	if !load(t, store, "work").IsRunning() {
+		t.Errorf("Toggle didn't start the selected timer")
+	}
+
+	press(t, d, "s")
+	if !strings.Contains(d.Render(), "Couldn't start work") {
+		t.Errorf("Starting a running timer didn't report an error:\n%s", d.Render())
+	}
+
+	press(t, d, "p")
+	if load(t, store, "work").IsRunning() {
+		t.Errorf("Stop didn't stop the selected timer")
+	}
+	if strings.Contains(d.Render(), "Couldn't") {
+		t.Errorf("A successful action didn't clear the error message:\n%s", d.Render())
+	}
+
+	state, err := store.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState returned an error: %v", err)
+	}
+	if state.Started == nil || state.Started.Name != "work" || state.Stopped == nil || state.Stopped.Name != "work" {
+		t.Errorf("Toggling didn't record the start and stop: got %v and %v", state.Started, state.Stopped)
+	}
+}
+
+func TestDashboard_CreateInvalidName(t *testing.T) {
+	store := timer.NewMemoryStore()
+	d := dashboard.New(store, freeze("2025-03-11T10:00:00Z"))
+	err := d.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh returned an error: %v", err)
+	}
+
+	press(t, d, "n", "/", "x", "/", "/", dashboard.KEY_ENTER)
+	all, _ := store.LoadAll()
+	if len(all) != 0 {
+		t.Errorf("Creating a timer with an invalid name didn't refuse it: got %v", all)
+	}
+	if !strings.Contains(d.Render(), "Couldn't create /x//") {
+		t.Errorf("Creating a timer with an invalid name didn't report an error:\n%s", d.Render())
+	}
+}
+
+func TestDashboard_Exclusive(t *testing.T) {
+	store := timer.NewMemoryStore()
+	for _, name := range []string{"alpha", "bravo"} {
+		_ = store.Dump(name, new(timer.Timer))
+	}
+
+	d := dashboard.New(store, freeze("2025-03-11T10:00:00Z"))
+	d.Exclusive = true
+	err := d.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh returned an error: %v", err)
+	}
+
+	press(t, d, "s", "j", "s")
+	if load(t, store, "alpha").IsRunning() || !load(t, store, "bravo").IsRunning() {
+		t.Errorf("Starting a timer didn't stop the other one")
+	}
+	if !strings.Contains(d.Render(), "Paused alpha") {
+		t.Errorf("Starting a timer didn't say which one it paused:\n%s", d.Render())
+	}
+
+	press(t, d, "k", " ")
+	if !load(t, store, "alpha").IsRunning() || load(t, store, "bravo").IsRunning() {
+		t.Errorf("Toggling a timer on didn't stop the other one")
+	}
+	if !strings.Contains(d.Render(), "Paused bravo") {
+		t.Errorf("Toggling a timer on didn't say which one it paused:\n%s", d.Render())
+	}
+
+	press(t, d, " ")
+	if load(t, store, "alpha").IsRunning() {
+		t.Errorf("Toggling a running timer didn't stop it")
+	}
+}
+
+func TestDashboard_ToggleError(t *testing.T) {
+	store := timer.NewMemoryStore()
+	future := &timer.Timer{
+		Segments: []timer.Segment{{
+			Start: freeze("2100-01-01T09:00:00Z").Moment,
+			End: freeze("2100-01-01T10:00:00Z").Moment,
+			Duration: time.Hour,
+		}},
+	}
+	err := store.Dump("imported", future)
+	if err != nil {
+		t.Fatalf("Couldn't dump timer: %v", err)
+	}
+
+	d := dashboard.New(store, freeze("2025-03-11T10:00:00Z"))
+	err = d.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh returned an error: %v", err)
+	}
+
+	press(t, d, " ")
+	if !strings.Contains(d.Render(), "Couldn't toggle imported") {
+		t.Errorf("Toggling into recorded time didn't report an error:\n%s", d.Render())
+	}
+	if load(t, store, "imported").IsRunning() {
+		t.Errorf("Toggle started a timer that overlaps its recorded time")
+	}
+}
+
+func TestDashboard_NavigateResetDelete(t *testing.T) {
+	store := timer.NewMemoryStore()
+	for _, name := range []string{"alpha", "bravo", "charlie"} {
+		ticks := new(timer.Timer)
+		_ = ticks.Start(freeze("2025-03-11T09:00:00Z"))
+		_ = ticks.Stop(freeze("2025-03-11T09:05:00Z"))
+		_ = store.Dump(name, ticks)
+	}
+
+	d := dashboard.New(store, freeze("2025-03-11T10:00:00Z"))
+	err := d.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh returned an error: %v", err)
+	}
+
+	press(t, d, "j", dashboard.KEY_DOWN, "j", "k", "r")
+	if load(t, store, "bravo").Elapsed() != 0 {
+		t.Errorf("Reset didn't reset the selected timer")
+	}
+	if load(t, store, "alpha").Elapsed() == 0 {
+		t.Errorf("Reset reset a timer that wasn't selected")
+	}
+
+	press(t, d, "d", "n")
+	exists, _ := store.Exists("bravo")
+	if !exists {
+		t.Errorf("Delete removed a timer even though it wasn't confirmed")
+	}
+
+	_ = store.UpdateState(func(state *timer.State) {
+		state.RecordStop("bravo", time.Time{})
+	})
+	press(t, d, "d", "y")
+	exists, _ = store.Exists("bravo")
+	if exists {
+		t.Errorf("Delete didn't remove the selected timer")
+	}
+	state, _ := store.LoadState()
+	if state.Stopped != nil {
+		t.Errorf("Delete didn't forget the timer in the state: got %v", state.Stopped)
+	}
+
+	rendered := d.Render()
+	if !strings.Contains(rendered, "> charlie") {
+		t.Errorf("Cursor didn't move to the next timer after a delete:\n%s", rendered)
+	}
+	if !strings.Contains(rendered, "  alpha            5m0s  stopped") {
+		t.Errorf("Dashboard didn't render timers correctly:\n%s", rendered)
+	}
+}
+
+func TestDashboard_Run(t *testing.T) {
+	store := timer.NewMemoryStore()
+	d := dashboard.New(store, freeze("2025-03-11T10:00:00Z"))
+
+	out := new(bytes.Buffer)
+	err := d.Run(context.Background(), strings.NewReader("nfocus\r\x1b[A\x1b[B q"), out, make(chan time.Time))
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	if !d.Done() {
+		t.Errorf("Run didn't stop when q was pressed")
+	}
+	if !load(t, store, "focus").IsRunning() {
+		t.Errorf("Run didn't apply keystrokes to the store")
+	}
+	if !strings.Contains(out.String(), "gowatch: 0 timers") {
+		t.Errorf("Run didn't draw the dashboard:\n%s", out.String())
+	}
+}
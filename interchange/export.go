@@ -0,0 +1,231 @@
+package interchange
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+const (
+	CSV		= "csv"
+	JSON	= "json"
+	ICS		= "ics"
+)
+
+var ExportFormats = []string{CSV, JSON, ICS}
+
+// DOCUMENT_VERSION is bumped whenever the export document changes in a way older readers can't handle.
+const DOCUMENT_VERSION = 1
+
+// Document is gowatch's own export format. It is also read back by the import command.
+type Document struct {
+	Version		int				`json:"version"`
+	Exported	time.Time		`json:"exported"`
+	Timers		[]ExportedTimer	`json:"timers"`
+}
+
+type ExportedTimer struct {
+	Name		string				`json:"name"`
+	Tags		[]string			`json:"tags,omitempty"`
+	Target		time.Duration		`json:"target_ns,omitempty"`
+	Intervals	[]ExportedInterval	`json:"intervals"`
+}
+
+type ExportedInterval struct {
+	Start		time.Time		`json:"start"`
+	End			*time.Time		`json:"end"`
+	Duration	time.Duration	`json:"duration_ns"`
+	Note		string			`json:"note,omitempty"`
+	Kind		string			`json:"kind,omitempty"`
+}
+
+// IsAdjustment reports whether the interval is a manual correction that covers no span of time.
+func (i *ExportedInterval) IsAdjustment() bool {
+	return i.End != nil && i.End.Equal(i.Start)
+}
+
+// NewDocument collects the timers' intervals, clipped to [since, until) like the report command does. A
+// zero since or until leaves that side of the range open. Running intervals are exported without an end.
+func NewDocument(nts []*timer.NamedTimer, since time.Time, until time.Time, nowProvider timer.NowProvider) *Document {
+	now := nowProvider.Now()
+	document := &Document{
+		Version: DOCUMENT_VERSION,
+		Exported: now,
+		Timers: make([]ExportedTimer, 0, len(nts)),
+	}
+
+	for _, nt := range nts {
+		exported := ExportedTimer{
+			Name: nt.Name,
+			Tags: nt.Ticks.Tags,
+			Target: nt.Ticks.Target,
+			Intervals: make([]ExportedInterval, 0, len(nt.Ticks.Segments)),
+		}
+
+		for i := range nt.Ticks.Segments {
+			segment, ok := nt.Ticks.Segments[i].Clip(since, until, now)
+			if !ok {
+				continue
+			}
+
+			interval := ExportedInterval{
+				Start: segment.Start,
+				Duration: segment.Duration,
+				Note: segment.Note,
+				Kind: segment.Kind,
+			}
+			if !segment.IsOpen() {
+				interval.End = &segment.End
+			}
+			exported.Intervals = append(exported.Intervals, interval)
+		}
+
+		if len(exported.Intervals) > 0 || (since.IsZero() && until.IsZero()) {
+			document.Timers = append(document.Timers, exported)
+		}
+	}
+	return document
+}
+
+func CheckExportFormat(format string) error {
+	if !slices.Contains(ExportFormats, format) {
+		return fmt.Errorf("Unknown export format %v: expected one of %v", format, ExportFormats)
+	}
+	return nil
+}
+
+func (d *Document) Write(w io.Writer, format string) error {
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(d)
+	case CSV:
+		return d.writeCSV(w)
+	case ICS:
+		return d.writeICS(w)
+	}
+	return fmt.Errorf("Can't export timers as %v", format)
+}
+
+func (d *Document) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"timer", "tags", "start", "end", "duration_ns", "duration", "note", "kind"})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range d.Timers {
+		for _, interval := range t.Intervals {
+			end := ""
+			if interval.End != nil {
+				end = interval.End.Format(time.RFC3339)
+			}
+			err = writer.Write([]string{
+				t.Name,
+				strings.Join(t.Tags, " "),
+				interval.Start.Format(time.RFC3339),
+				end,
+				strconv.FormatInt(int64(interval.Duration), 10),
+				interval.Duration.Round(time.Second).String(),
+				interval.Note,
+				interval.Kind,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+const icsTimeLayout = "20060102T150405Z"
+
+func escapeICS(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return replacer.Replace(text)
+}
+
+// foldICS splits content lines longer than 75 octets, as RFC 5545 requires.
+func foldICS(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	builder := new(strings.Builder)
+	width := 75
+	for len(line) > width {
+		cut := width
+		// Don't split a multi-byte character.
+		for cut > 0 && line[cut] & 0xC0 == 0x80 {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		width = 74
+	}
+	builder.WriteString(line)
+	return builder.String()
+}
+
+func (d *Document) writeICS(w io.Writer) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//dusktreader//gowatch//EN",
+		"CALSCALE:GREGORIAN",
+	}
+
+	stamp := d.Exported.UTC().Format(icsTimeLayout)
+	for _, t := range d.Timers {
+		for _, interval := range t.Intervals {
+			// Adjustments don't cover any time, so there's nothing to put on a calendar.
+			if interval.IsAdjustment() {
+				continue
+			}
+			end := interval.Start.Add(interval.Duration)
+			uid := fmt.Sprintf("%x@gowatch", sha1.Sum([]byte(t.Name + "\x00" + interval.Start.UTC().Format(time.RFC3339Nano))))
+
+			lines = append(
+				lines,
+				"BEGIN:VEVENT",
+				"UID:" + uid,
+				"DTSTAMP:" + stamp,
+				"DTSTART:" + interval.Start.UTC().Format(icsTimeLayout),
+				"DTEND:" + end.UTC().Format(icsTimeLayout),
+				"SUMMARY:" + escapeICS(t.Name),
+			)
+			if interval.Note != "" {
+				lines = append(lines, "DESCRIPTION:" + escapeICS(interval.Note))
+			}
+			if len(t.Tags) > 0 {
+				escaped := make([]string, 0, len(t.Tags))
+				for _, tag := range t.Tags {
+					escaped = append(escaped, escapeICS(tag))
+				}
+				lines = append(lines, "CATEGORIES:" + strings.Join(escaped, ","))
+			}
+			lines = append(lines, "END:VEVENT")
+		}
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		_, err := io.WriteString(w, foldICS(line) + "\r\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
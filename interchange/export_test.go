@@ -0,0 +1,141 @@
+package interchange_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/interchange"
+	"github.com/dusktreader/gowatch/timer"
+)
+
+var update = flag.Bool("update", false, "Update golden files")
+
+func moment(m string) time.Time {
+	t, err := time.Parse(time.RFC3339, m)
+	if err != nil {
+		panic("Couldn't parse time: " + m)
+	}
+	return t
+}
+
+func segment(start string, end string, note string) timer.Segment {
+	s := timer.Segment{Start: moment(start), Note: note}
+	if end != "" {
+		s.End = moment(end)
+		s.Duration = s.End.Sub(s.Start)
+	}
+	return s
+}
+
+func fixtures() []*timer.NamedTimer {
+	return []*timer.NamedTimer{
+		{
+			Name: "acme/backend",
+			Ticks: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-09-01T09:00:00Z", "2025-09-01T10:30:00Z", "fixed the build, finally; shipped"),
+					segment("2025-09-02T09:00:00Z", "", ""),
+				},
+				Tags: []string{"client-a", "dev"},
+			},
+		},
+		{
+			Name: "standup",
+			Ticks: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-09-01T08:45:00Z", "2025-09-01T09:00:00Z", "a rather long note that will need to be folded when it is written to an ics file"),
+					{
+						Start: moment("2025-09-01T12:00:00Z"),
+						End: moment("2025-09-01T12:00:00Z"),
+						Duration: 5 * time.Minute,
+						Kind: timer.MANUAL,
+					},
+				},
+			},
+		},
+	}
+}
+
+var now = timer.FixedNowProvider{Moment: moment("2025-09-02T10:00:00Z")}
+
+func golden(t *testing.T, name string, got []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		err := os.WriteFile(path, got, 0644)
+		if err != nil {
+			t.Fatalf("Couldn't update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Couldn't read golden file: %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("Output didn't match golden file %v:\n\nwanted\n%s\n\ngot\n%s", path, want, got)
+	}
+}
+
+func TestDocument_Write(t *testing.T) {
+	document := interchange.NewDocument(fixtures(), time.Time{}, time.Time{}, now)
+
+	for _, format := range interchange.ExportFormats {
+		t.Run(format, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			err := document.Write(buffer, format)
+			if err != nil {
+				t.Fatalf("Write returned an error: %v", err)
+			}
+			golden(t, "export." + format, buffer.Bytes())
+		})
+	}
+}
+
+func TestDocument_ICSFolding(t *testing.T) {
+	document := interchange.NewDocument(fixtures(), time.Time{}, time.Time{}, now)
+
+	buffer := new(bytes.Buffer)
+	err := document.Write(buffer, interchange.ICS)
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	for _, line := range strings.Split(buffer.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("ICS line wasn't folded: %q", line)
+		}
+	}
+}
+
+func TestNewDocument_Range(t *testing.T) {
+	document := interchange.NewDocument(
+		fixtures(),
+		moment("2025-09-01T08:50:00Z"),
+		moment("2025-09-01T10:00:00Z"),
+		now,
+	)
+
+	if len(document.Timers) != 2 {
+		t.Fatalf("NewDocument returned the wrong timers: %v", document.Timers)
+	}
+
+	backend := document.Timers[0].Intervals
+	if len(backend) != 1 || backend[0].Duration != time.Hour || backend[0].End == nil {
+		t.Errorf("NewDocument didn't clip the interval to the range: %v", backend)
+	}
+
+	standup := document.Timers[1].Intervals
+	if len(standup) != 1 || !standup[0].Start.Equal(moment("2025-09-01T08:50:00Z")) {
+		t.Errorf("NewDocument didn't clip the interval start to the range: %v", standup)
+	}
+
+	document = interchange.NewDocument(fixtures(), moment("2025-10-01T00:00:00Z"), time.Time{}, now)
+	if len(document.Timers) != 0 {
+		t.Errorf("NewDocument included timers without intervals in the range: %v", document.Timers)
+	}
+}
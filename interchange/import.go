@@ -0,0 +1,516 @@
+package interchange
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+const (
+	TIMEWARRIOR	= "timewarrior"
+	TOGGL		= "toggl"
+	GOWATCH		= "gowatch"
+)
+
+var ImportFormats = []string{TIMEWARRIOR, TOGGL, GOWATCH}
+
+const (
+	ON_CONFLICT_SKIP	= "skip"
+	ON_CONFLICT_MERGE	= "merge"
+	ON_CONFLICT_RENAME	= "rename"
+)
+
+var ConflictModes = []string{ON_CONFLICT_SKIP, ON_CONFLICT_MERGE, ON_CONFLICT_RENAME}
+
+const (
+	CREATED	= "created"
+	MERGED	= "merged"
+	RENAMED	= "renamed"
+	SKIPPED	= "skipped"
+)
+
+func CheckImportFormat(format string) error {
+	if !slices.Contains(ImportFormats, format) {
+		return fmt.Errorf("Unknown import format %v: expected one of %v", format, ImportFormats)
+	}
+	return nil
+}
+
+func CheckConflictMode(mode string) error {
+	if !slices.Contains(ConflictModes, mode) {
+		return fmt.Errorf("Unknown conflict mode %v: expected one of %v", mode, ConflictModes)
+	}
+	return nil
+}
+
+// GuessImportFormat picks the import format from a file's extension, returning "" when it can't tell.
+func GuessImportFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".data":
+		return TIMEWARRIOR
+	case ".csv":
+		return TOGGL
+	case ".json":
+		return GOWATCH
+	}
+	return ""
+}
+
+// Read parses an import source into a Document. Times without a zone, as in Toggl exports, are read in loc.
+func Read(r io.Reader, format string, loc *time.Location) (*Document, error) {
+	switch format {
+	case TIMEWARRIOR:
+		return ReadTimewarrior(r)
+	case TOGGL:
+		return ReadToggl(r, loc)
+	case GOWATCH:
+		return ReadDocument(r)
+	}
+	return nil, fmt.Errorf("Can't import timers from %v", format)
+}
+
+func ReadDocument(r io.Reader) (*Document, error) {
+	document := &Document{}
+	err := json.NewDecoder(r).Decode(document)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't decode gowatch export: %v", err)
+	}
+	if document.Version > DOCUMENT_VERSION {
+		return nil, fmt.Errorf(
+			"Export document version %d is newer than the supported version %d",
+			document.Version,
+			DOCUMENT_VERSION,
+		)
+	}
+	for _, t := range document.Timers {
+		err = timer.CheckName(t.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return document, nil
+}
+
+// collector groups intervals read from a flat source into one exported timer per name.
+type collector struct {
+	timers	map[string]*ExportedTimer
+}
+
+func newCollector() *collector {
+	return &collector{timers: map[string]*ExportedTimer{}}
+}
+
+func (c *collector) add(name string, tags []string, interval ExportedInterval) {
+	t, ok := c.timers[name]
+	if !ok {
+		t = &ExportedTimer{Name: name, Intervals: []ExportedInterval{}}
+		c.timers[name] = t
+	}
+	for _, tag := range tags {
+		if !slices.Contains(t.Tags, tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+	slices.Sort(t.Tags)
+	t.Intervals = append(t.Intervals, interval)
+}
+
+func (c *collector) document() *Document {
+	document := &Document{Version: DOCUMENT_VERSION, Timers: make([]ExportedTimer, 0, len(c.timers))}
+	for _, t := range c.timers {
+		slices.SortFunc(t.Intervals, func(a ExportedInterval, b ExportedInterval) int {
+			return a.Start.Compare(b.Start)
+		})
+		document.Timers = append(document.Timers, *t)
+	}
+	slices.SortFunc(document.Timers, func(a ExportedTimer, b ExportedTimer) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return document
+}
+
+// importTag turns a foreign tag into a valid gowatch tag by replacing whitespace and commas with dashes.
+func importTag(text string) string {
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}), "-")
+}
+
+const timewarriorLayout = "20060102T150405Z"
+
+// splitTimewarriorTags splits the tag list of a Timewarrior interval, honoring double quotes.
+func splitTimewarriorTags(text string) []string {
+	tags := []string{}
+	builder := new(strings.Builder)
+	quoted := false
+	escaped := false
+	flush := func() {
+		if builder.Len() > 0 {
+			tags = append(tags, builder.String())
+			builder.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case escaped:
+			builder.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			flush()
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	flush()
+	return tags
+}
+
+// ReadTimewarrior reads a Timewarrior data file, where each line looks like
+//
+//	inc 20251010T100000Z - 20251010T110000Z # acme "code review" # "annotation"
+//
+// The first tag names the timer and the remaining tags become its tags. Untagged intervals go to the
+// default timer. Intervals without an end are still running.
+func ReadTimewarrior(r io.Reader) (*Document, error) {
+	c := newCollector()
+	scanner := bufio.NewScanner(r)
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		head, rest, _ := strings.Cut(line, " # ")
+		fields := strings.Fields(head)
+		if len(fields) == 0 || fields[0] != "inc" || (len(fields) != 2 && len(fields) != 4) {
+			return nil, fmt.Errorf("Couldn't parse Timewarrior interval on line %d: %q", number, line)
+		}
+
+		start, err := time.Parse(timewarriorLayout, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse Timewarrior start on line %d: %v", number, err)
+		}
+		interval := ExportedInterval{Start: start}
+		if len(fields) == 4 {
+			end, err := time.Parse(timewarriorLayout, fields[3])
+			if err != nil || fields[2] != "-" {
+				return nil, fmt.Errorf("Couldn't parse Timewarrior end on line %d: %q", number, line)
+			}
+			interval.End = &end
+			interval.Duration = end.Sub(start)
+		}
+
+		tagText, annotation, _ := strings.Cut(rest, " # ")
+		if annotation != "" {
+			interval.Note = strings.Join(splitTimewarriorTags(annotation), " ")
+		}
+
+		name := timer.DEFAULT_TIMER_NAME
+		tags := []string{}
+		for i, tag := range splitTimewarriorTags(tagText) {
+			if i == 0 {
+				name = tag
+			} else {
+				tags = append(tags, importTag(tag))
+			}
+		}
+		err = timer.CheckName(name)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't use Timewarrior tag as a timer name on line %d: %v", number, err)
+		}
+
+		c.add(name, tags, interval)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read Timewarrior data: %v", err)
+	}
+	return c.document(), nil
+}
+
+const (
+	togglDateLayout		= "2006-01-02"
+	togglTimeLayout		= "15:04:05"
+)
+
+// ReadToggl reads a Toggl detailed CSV export. The project names the timer, falling back to the
+// description, which otherwise becomes the interval's note. Toggl writes local times without a zone, so
+// they are read in loc.
+func ReadToggl(r io.Reader, loc *time.Location) (*Document, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read Toggl CSV header: %v", err)
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, column := range []string{"start date", "start time"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("Toggl CSV is missing the %q column", column)
+		}
+	}
+
+	c := newCollector()
+	number := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		number++
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read Toggl CSV line %d: %v", number, err)
+		}
+
+		field := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		start, err := time.ParseInLocation(
+			togglDateLayout + " " + togglTimeLayout,
+			field("start date") + " " + field("start time"),
+			loc,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse Toggl start on line %d: %v", number, err)
+		}
+
+		interval := ExportedInterval{Start: start}
+		if field("end date") != "" && field("end time") != "" {
+			end, err := time.ParseInLocation(
+				togglDateLayout + " " + togglTimeLayout,
+				field("end date") + " " + field("end time"),
+				loc,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("Couldn't parse Toggl end on line %d: %v", number, err)
+			}
+			interval.End = &end
+		} else if field("duration") != "" {
+			var hours, minutes, seconds int
+			_, err := fmt.Sscanf(field("duration"), "%d:%d:%d", &hours, &minutes, &seconds)
+			if err != nil {
+				return nil, fmt.Errorf("Couldn't parse Toggl duration on line %d: %v", number, err)
+			}
+			end := start.Add(time.Duration(hours) * time.Hour + time.Duration(minutes) * time.Minute + time.Duration(seconds) * time.Second)
+			interval.End = &end
+		}
+		if interval.End != nil {
+			if interval.End.Before(start) {
+				return nil, fmt.Errorf("Toggl interval on line %d ends before it starts", number)
+			}
+			interval.Duration = interval.End.Sub(start)
+		}
+
+		name := field("project")
+		if name == "" {
+			name = field("description")
+		} else {
+			interval.Note = field("description")
+		}
+		if name == "" {
+			name = timer.DEFAULT_TIMER_NAME
+		}
+		err = timer.CheckName(name)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't use Toggl project as a timer name on line %d: %v", number, err)
+		}
+
+		tags := []string{}
+		for _, tag := range strings.Split(field("tags"), ",") {
+			tag = importTag(tag)
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+
+		c.add(name, tags, interval)
+	}
+	return c.document(), nil
+}
+
+// Change describes what importing one timer from a document did, or would do in a dry run.
+type Change struct {
+	Source		string
+	Name		string
+	Action		string
+	Intervals	int
+	Duplicates	int
+	Overlaps	int
+	Duration	time.Duration
+}
+
+func segmentsFrom(intervals []ExportedInterval) []timer.Segment {
+	segments := make([]timer.Segment, 0, len(intervals))
+	for _, interval := range intervals {
+		segment := timer.Segment{Start: interval.Start, Note: interval.Note, Kind: interval.Kind}
+		if interval.End != nil {
+			segment.End = *interval.End
+			segment.Duration = interval.End.Sub(interval.Start)
+		}
+		if interval.IsAdjustment() {
+			segment.Duration = interval.Duration
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+func byStart(a timer.Segment, b timer.Segment) int {
+	return a.Start.Compare(b.Start)
+}
+
+// mergeSegments adds the incoming segments to the existing ones in start order and counts them on the
+// change. Segments that start at the same moment as one already there are duplicates, so importing the same
+// file twice changes nothing. Segments that overlap recorded time, including a running segment, are skipped
+// like AddInterval refuses them, so existing segments are never changed. Only the last incoming segment may
+// stay open, so any other open one is closed where the next one starts.
+func mergeSegments(existing []timer.Segment, incoming []timer.Segment, change *Change) []timer.Segment {
+	incoming = slices.Clone(incoming)
+	slices.SortStableFunc(incoming, byStart)
+	for i := 0; i < len(incoming) - 1; i++ {
+		if incoming[i].IsOpen() {
+			incoming[i].End = incoming[i + 1].Start
+			incoming[i].Duration = incoming[i].End.Sub(incoming[i].Start)
+		}
+	}
+
+	merged := slices.Clone(existing)
+	for _, segment := range incoming {
+		if slices.ContainsFunc(merged, func(s timer.Segment) bool { return s.Start.Equal(segment.Start) }) {
+			change.Duplicates++
+			continue
+		}
+		if slices.ContainsFunc(merged, func(s timer.Segment) bool { return segment.Overlaps(&s) }) {
+			slog.Debug("Skipping overlapping interval", "Name", change.Name, "Start", segment.Start)
+			change.Overlaps++
+			continue
+		}
+		merged = append(merged, segment)
+		change.Intervals++
+	}
+
+	slices.SortStableFunc(merged, byStart)
+	return merged
+}
+
+// freeName finds the first of name-2, name-3, ... that isn't taken, locking it before returning.
+func freeName(s timer.Store, name string) (string, timer.Unlocker, error) {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		lock, err := s.Lock(candidate)
+		if err != nil {
+			return "", nil, err
+		}
+
+		exists, err := s.Exists(candidate)
+		if err != nil {
+			_ = lock.Unlock()
+			return "", nil, err
+		}
+		if !exists {
+			return candidate, lock, nil
+		}
+		_ = lock.Unlock()
+	}
+}
+
+// Import writes the document's timers into the store. Timers that already exist are skipped, merged or
+// imported under a new name depending on onConflict. With dryRun set, nothing is written but the returned
+// changes still describe what would have happened.
+func Import(s timer.Store, document *Document, onConflict string, dryRun bool) ([]Change, error) {
+	err := CheckConflictMode(onConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0, len(document.Timers))
+	for _, imported := range document.Timers {
+		change, err := importTimer(s, imported, onConflict, dryRun)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func importTimer(s timer.Store, imported ExportedTimer, onConflict string, dryRun bool) (Change, error) {
+	change := Change{Source: imported.Name, Name: imported.Name, Action: CREATED}
+
+	lock, err := s.Lock(imported.Name)
+	if err != nil {
+		return change, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	exists, err := s.Exists(imported.Name)
+	if err != nil {
+		return change, err
+	}
+
+	t, err := s.Load(imported.Name)
+	if err != nil {
+		return change, err
+	}
+	if exists {
+		switch onConflict {
+		case ON_CONFLICT_SKIP:
+			change.Action = SKIPPED
+			return change, nil
+		case ON_CONFLICT_MERGE:
+			change.Action = MERGED
+		case ON_CONFLICT_RENAME:
+			_ = lock.Unlock()
+			change.Name, lock, err = freeName(s, imported.Name)
+			if err != nil {
+				return change, err
+			}
+			change.Action = RENAMED
+			t = &timer.Timer{}
+		}
+	}
+
+	np := timer.FixedNowProvider{Moment: time.Now()}
+	before := t.Elapsed(np)
+	t.Segments = mergeSegments(t.Segments, segmentsFrom(imported.Intervals), &change)
+	change.Duration = t.Elapsed(np) - before
+	if t.Target == 0 {
+		t.Target = imported.Target
+	}
+	err = t.AddTags(imported.Tags...)
+	if err != nil {
+		return change, err
+	}
+
+	if dryRun {
+		return change, nil
+	}
+
+	slog.Debug("Importing timer", "Name", change.Name, "Action", change.Action, "Intervals", change.Intervals)
+	return change, s.Dump(change.Name, t)
+}
@@ -0,0 +1,289 @@
+package interchange_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/interchange"
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func readFixture(t *testing.T, name string, format string) *interchange.Document {
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Couldn't open fixture: %v", err)
+	}
+	defer file.Close()
+
+	document, err := interchange.Read(file, format, time.UTC)
+	if err != nil {
+		t.Fatalf("Read returned an error: %v", err)
+	}
+	return document
+}
+
+func TestGuessImportFormat(t *testing.T) {
+	cases := map[string]string{
+		"2025-09.data": interchange.TIMEWARRIOR,
+		"Toggl.CSV": interchange.TOGGL,
+		"export.json": interchange.GOWATCH,
+		"notes.txt": "",
+	}
+	for path, want := range cases {
+		got := interchange.GuessImportFormat(path)
+		if got != want {
+			t.Errorf("GuessImportFormat didn't guess the format of %v: wanted %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestReadTimewarrior(t *testing.T) {
+	document := readFixture(t, "timewarrior.data", interchange.TIMEWARRIOR)
+
+	if len(document.Timers) != 2 {
+		t.Fatalf("ReadTimewarrior didn't group intervals by timer: %v", document.Timers)
+	}
+
+	backend := document.Timers[0]
+	if backend.Name != "acme/backend" {
+		t.Errorf("ReadTimewarrior didn't name the timer after the first tag: got %v", backend.Name)
+	}
+	if !slices.Equal(backend.Tags, []string{"billable", "code-review"}) {
+		t.Errorf("ReadTimewarrior didn't convert the remaining tags: got %v", backend.Tags)
+	}
+	if len(backend.Intervals) != 2 {
+		t.Fatalf("ReadTimewarrior returned the wrong intervals: %v", backend.Intervals)
+	}
+	if backend.Intervals[0].Duration != 90 * time.Minute || backend.Intervals[0].Note != "fixed the build" {
+		t.Errorf("ReadTimewarrior didn't read the closed interval: got %v", backend.Intervals[0])
+	}
+	if backend.Intervals[1].End != nil {
+		t.Errorf("ReadTimewarrior didn't leave the running interval open: got %v", backend.Intervals[1])
+	}
+
+	if document.Timers[1].Name != timer.DEFAULT_TIMER_NAME {
+		t.Errorf("ReadTimewarrior didn't put untagged intervals in the default timer: got %v", document.Timers[1].Name)
+	}
+}
+
+func TestReadTimewarrior_Malformed(t *testing.T) {
+	_, err := interchange.ReadTimewarrior(strings.NewReader("inc yesterday - today\n"))
+	if err == nil {
+		t.Errorf("ReadTimewarrior didn't reject a malformed interval")
+	}
+}
+
+func TestReadToggl(t *testing.T) {
+	document := readFixture(t, "toggl.csv", interchange.TOGGL)
+
+	if len(document.Timers) != 2 {
+		t.Fatalf("ReadToggl returned the wrong timers: %v", document.Timers)
+	}
+
+	backend := document.Timers[0]
+	if backend.Name != "acme/backend" || !slices.Equal(backend.Tags, []string{"billable", "code-review"}) {
+		t.Errorf("ReadToggl didn't read the project and tags: got %v", backend)
+	}
+	if backend.Intervals[0].Note != "Fixed the build" || backend.Intervals[0].Duration != 90 * time.Minute {
+		t.Errorf("ReadToggl didn't read the interval: got %v", backend.Intervals[0])
+	}
+
+	standup := document.Timers[1]
+	if standup.Name != "standup" || standup.Intervals[0].Duration != 15 * time.Minute {
+		t.Errorf("ReadToggl didn't fall back to the description and duration: got %v", standup)
+	}
+}
+
+func TestReadDocument_RoundTrip(t *testing.T) {
+	exported := interchange.NewDocument(fixtures(), time.Time{}, time.Time{}, now)
+	buffer := new(bytes.Buffer)
+	err := exported.Write(buffer, interchange.JSON)
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	s := timer.NewMemoryStore()
+	document, err := interchange.ReadDocument(buffer)
+	if err != nil {
+		t.Fatalf("ReadDocument returned an error: %v", err)
+	}
+	_, err = interchange.Import(s, document, interchange.ON_CONFLICT_SKIP, false)
+	if err != nil {
+		t.Fatalf("Import returned an error: %v", err)
+	}
+
+	for _, nt := range fixtures() {
+		got, err := s.Load(nt.Name, true)
+		if err != nil {
+			t.Fatalf("Import didn't create timer %v: %v", nt.Name, err)
+		}
+		if !slices.Equal(got.Segments, nt.Ticks.Segments) || !slices.Equal(got.Tags, nt.Ticks.Tags) {
+			t.Errorf("Import didn't round trip timer %v: wanted %v, got %v", nt.Name, nt.Ticks, got)
+		}
+	}
+}
+
+func TestReadDocument_NewerVersion(t *testing.T) {
+	_, err := interchange.ReadDocument(strings.NewReader(`{"version": 99, "timers": []}`))
+	if err == nil {
+		t.Errorf("ReadDocument didn't reject a newer document version")
+	}
+}
+
+func existing(t *testing.T) timer.Store {
+	s := timer.NewMemoryStore()
+	err := s.Dump("standup", &timer.Timer{
+		Segments: []timer.Segment{segment("2025-09-01T08:45:00Z", "2025-09-01T09:00:00Z", "")},
+	})
+	if err != nil {
+		t.Fatalf("Couldn't dump timer: %v", err)
+	}
+	return s
+}
+
+func TestImport_Conflicts(t *testing.T) {
+	document := &interchange.Document{
+		Version: interchange.DOCUMENT_VERSION,
+		Timers: []interchange.ExportedTimer{
+			{
+				Name: "standup",
+				Tags: []string{"meeting"},
+				Intervals: []interchange.ExportedInterval{
+					{Start: moment("2025-09-01T08:45:00Z"), Duration: 15 * time.Minute},
+					{Start: moment("2025-09-02T08:45:00Z"), Duration: 0},
+				},
+			},
+		},
+	}
+	end := moment("2025-09-01T09:00:00Z")
+	document.Timers[0].Intervals[0].End = &end
+
+	t.Run("skip", func(t *testing.T) {
+		s := existing(t)
+		changes, err := interchange.Import(s, document, interchange.ON_CONFLICT_SKIP, false)
+		if err != nil {
+			t.Fatalf("Import returned an error: %v", err)
+		}
+		if changes[0].Action != interchange.SKIPPED {
+			t.Errorf("Import didn't skip the existing timer: got %v", changes[0])
+		}
+		got, _ := s.Load("standup")
+		if len(got.Segments) != 1 {
+			t.Errorf("Import changed a skipped timer: got %v", got)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		s := existing(t)
+		changes, err := interchange.Import(s, document, interchange.ON_CONFLICT_MERGE, false)
+		if err != nil {
+			t.Fatalf("Import returned an error: %v", err)
+		}
+		if changes[0].Action != interchange.MERGED || changes[0].Intervals != 1 || changes[0].Duplicates != 1 {
+			t.Errorf("Import didn't merge the new interval only: got %v", changes[0])
+		}
+		got, _ := s.Load("standup")
+		if len(got.Segments) != 2 || !got.IsRunning() || !got.HasTag("meeting") {
+			t.Errorf("Import didn't merge into the existing timer: got %v", got)
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		s := existing(t)
+		changes, err := interchange.Import(s, document, interchange.ON_CONFLICT_RENAME, false)
+		if err != nil {
+			t.Fatalf("Import returned an error: %v", err)
+		}
+		if changes[0].Action != interchange.RENAMED || changes[0].Name != "standup-2" {
+			t.Errorf("Import didn't rename the imported timer: got %v", changes[0])
+		}
+		got, err := s.Load("standup-2", true)
+		if err != nil || len(got.Segments) != 2 {
+			t.Errorf("Import didn't create the renamed timer: got %v (%v)", got, err)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		s := existing(t)
+		changes, err := interchange.Import(s, document, interchange.ON_CONFLICT_MERGE, true)
+		if err != nil {
+			t.Fatalf("Import returned an error: %v", err)
+		}
+		if changes[0].Action != interchange.MERGED || changes[0].Intervals != 1 {
+			t.Errorf("Import didn't report the change in a dry run: got %v", changes[0])
+		}
+		got, _ := s.Load("standup")
+		if len(got.Segments) != 1 {
+			t.Errorf("Import wrote to the store in a dry run: got %v", got)
+		}
+	})
+}
+
+func TestImport_ClosesEarlierOpenIntervals(t *testing.T) {
+	s := timer.NewMemoryStore()
+	document := &interchange.Document{
+		Timers: []interchange.ExportedTimer{
+			{
+				Name: "default",
+				Intervals: []interchange.ExportedInterval{
+					{Start: moment("2025-09-01T09:00:00Z")},
+					{Start: moment("2025-09-01T10:00:00Z")},
+				},
+			},
+		},
+	}
+
+	_, err := interchange.Import(s, document, interchange.ON_CONFLICT_SKIP, false)
+	if err != nil {
+		t.Fatalf("Import returned an error: %v", err)
+	}
+
+	got, _ := s.Load("default")
+	if got.Segments[0].IsOpen() || got.Segments[0].Duration != time.Hour || !got.Segments[1].IsOpen() {
+		t.Errorf("Import didn't close the earlier open interval: got %v", got.Segments)
+	}
+}
+
+func TestImport_MergeOverlaps(t *testing.T) {
+	s := timer.NewMemoryStore()
+	err := s.Dump("busy", &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-09-01T10:00:00Z", "2025-09-01T11:00:00Z", ""),
+			segment("2025-09-01T12:00:00Z", "", ""),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Couldn't dump timer: %v", err)
+	}
+
+	interval := func(start string, end string) interchange.ExportedInterval {
+		stop := moment(end)
+		return interchange.ExportedInterval{Start: moment(start), End: &stop}
+	}
+	early := interval("2025-09-01T08:00:00Z", "2025-09-01T09:00:00Z")
+	late := interval("2025-09-01T10:30:00Z", "2025-09-01T11:30:00Z")
+	running := interval("2025-09-01T13:00:00Z", "2025-09-01T13:30:00Z")
+
+	document := &interchange.Document{
+		Timers: []interchange.ExportedTimer{
+			{Name: "busy", Intervals: []interchange.ExportedInterval{early, late, running}},
+		},
+	}
+	changes, err := interchange.Import(s, document, interchange.ON_CONFLICT_MERGE, false)
+	if err != nil {
+		t.Fatalf("Import returned an error: %v", err)
+	}
+	if changes[0].Intervals != 1 || changes[0].Overlaps != 2 || changes[0].Duration != time.Hour {
+		t.Errorf("Import didn't skip the overlapping intervals: got %+v", changes[0])
+	}
+
+	got, _ := s.Load("busy")
+	if len(got.Segments) != 3 || !got.IsRunning() || !got.Current().Start.Equal(moment("2025-09-01T12:00:00Z")) {
+		t.Errorf("Import changed the running timer: got %v", got.Segments)
+	}
+}
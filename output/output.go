@@ -0,0 +1,237 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	TEXT	= "text"
+	JSON	= "json"
+	YAML	= "yaml"
+	CSV		= "csv"
+)
+
+var Formats = []string{TEXT, JSON, YAML, CSV}
+
+// Record is the machine-readable view of a timer. Its fields and their names are part of the documented
+// output of `show` and `list`, so changes here must stay backwards compatible.
+type Record struct {
+	Name			string			`json:"name" yaml:"name"`
+	Running			bool			`json:"running" yaml:"running"`
+	Start			*time.Time		`json:"start" yaml:"start"`
+	End				*time.Time		`json:"end" yaml:"end"`
+	Elapsed			time.Duration	`json:"elapsed_ns" yaml:"elapsed_ns"`
+	ElapsedString	string			`json:"elapsed" yaml:"elapsed"`
+	Laps			[]LapRecord		`json:"laps,omitempty" yaml:"laps,omitempty"`
+	Tags			[]string		`json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type LapRecord struct {
+	Name	string			`json:"name,omitempty" yaml:"name,omitempty"`
+	Time	time.Time		`json:"time" yaml:"time"`
+	Split	time.Duration	`json:"split_ns" yaml:"split_ns"`
+	Elapsed	time.Duration	`json:"elapsed_ns" yaml:"elapsed_ns"`
+}
+
+// MarshalYAML keeps elapsed_ns numeric. The YAML encoder would otherwise write durations as strings.
+func (r Record) MarshalYAML() (any, error) {
+	type yamlRecord struct {
+		Name			string		`yaml:"name"`
+		Running			bool		`yaml:"running"`
+		Start			*time.Time	`yaml:"start"`
+		End				*time.Time	`yaml:"end"`
+		Elapsed			int64		`yaml:"elapsed_ns"`
+		ElapsedString	string		`yaml:"elapsed"`
+		Laps			[]LapRecord	`yaml:"laps,omitempty"`
+		Tags			[]string	`yaml:"tags,omitempty"`
+	}
+	return yamlRecord{
+		Name: r.Name,
+		Running: r.Running,
+		Start: r.Start,
+		End: r.End,
+		Elapsed: int64(r.Elapsed),
+		ElapsedString: r.ElapsedString,
+		Laps: r.Laps,
+		Tags: r.Tags,
+	}, nil
+}
+
+func (l LapRecord) MarshalYAML() (any, error) {
+	type yamlLapRecord struct {
+		Name	string		`yaml:"name,omitempty"`
+		Time	time.Time	`yaml:"time"`
+		Split	int64		`yaml:"split_ns"`
+		Elapsed	int64		`yaml:"elapsed_ns"`
+	}
+	return yamlLapRecord{
+		Name: l.Name,
+		Time: l.Time,
+		Split: int64(l.Split),
+		Elapsed: int64(l.Elapsed),
+	}, nil
+}
+
+var csvHeader = []string{"name", "running", "start", "end", "elapsed_ns", "elapsed"}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func NewRecord(name string, t *timer.Timer, nowProviderArg ...timer.NowProvider) Record {
+	record := Record{
+		Name: name,
+		Running: t.IsRunning(),
+		Start: optionalTime(t.Started()),
+		End: optionalTime(t.Ended()),
+		Elapsed: t.Elapsed(nowProviderArg...),
+		ElapsedString: t.ElapsedString(nowProviderArg...),
+		Tags: t.Tags,
+	}
+	for i, lap := range t.Laps {
+		record.Laps = append(
+			record.Laps,
+			LapRecord{
+				Name: lap.Name,
+				Time: lap.Time,
+				Split: t.Split(i),
+				Elapsed: lap.Elapsed,
+			},
+		)
+	}
+	return record
+}
+
+// NewNodeRecord rolls up a timer and all of its descendants into a single record.
+func NewNodeRecord(node *timer.Node, nowProviderArg ...timer.NowProvider) Record {
+	var start time.Time
+	var end time.Time
+	node.Walk(func(n *timer.Node, _ int) {
+		if n.Ticks == nil {
+			return
+		}
+		started := n.Ticks.Started()
+		if !started.IsZero() && (start.IsZero() || started.Before(start)) {
+			start = started
+		}
+		if n.Ticks.Ended().After(end) {
+			end = n.Ticks.Ended()
+		}
+	})
+
+	running := node.IsRunning()
+	if running {
+		end = time.Time{}
+	}
+
+	elapsed := node.Elapsed(nowProviderArg...)
+	return Record{
+		Name: node.Path,
+		Running: running,
+		Start: optionalTime(start),
+		End: optionalTime(end),
+		Elapsed: elapsed,
+		ElapsedString: elapsed.Round(timer.Precision).String(),
+	}
+}
+
+func NewRecords(nts []*timer.NamedTimer, nowProviderArg ...timer.NowProvider) []Record {
+	records := make([]Record, 0, len(nts))
+	for _, nt := range nts {
+		records = append(records, NewRecord(nt.Name, nt.Ticks, nowProviderArg...))
+	}
+	return records
+}
+
+func CheckFormat(format string) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("Unknown output format %v: expected one of %v", format, Formats)
+	}
+	return nil
+}
+
+// WriteRecord writes a single record, as emitted by `show`.
+func WriteRecord(w io.Writer, format string, record Record) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, record)
+	case YAML:
+		return writeYAML(w, record)
+	case CSV:
+		return writeCSV(w, []Record{record})
+	}
+	return fmt.Errorf("Can't write records as %v", format)
+}
+
+// WriteRecords writes a list of records, as emitted by `list`.
+func WriteRecords(w io.Writer, format string, records []Record) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, records)
+	case YAML:
+		return writeYAML(w, records)
+	case CSV:
+		return writeCSV(w, records)
+	}
+	return fmt.Errorf("Can't write records as %v", format)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeYAML(w io.Writer, v any) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(v)
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func writeCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		err = writer.Write([]string{
+			record.Name,
+			strconv.FormatBool(record.Running),
+			formatTime(record.Start),
+			formatTime(record.End),
+			strconv.FormatInt(int64(record.Elapsed), 10),
+			record.ElapsedString,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
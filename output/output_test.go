@@ -0,0 +1,122 @@
+package output_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/output"
+	"github.com/dusktreader/gowatch/timer"
+)
+
+var update = flag.Bool("update", false, "Update golden files")
+
+func moment(m string) time.Time {
+	t, err := time.Parse(time.RFC3339, m)
+	if err != nil {
+		panic("Couldn't parse time: " + m)
+	}
+	return t
+}
+
+func segment(start string, end string) timer.Segment {
+	s := timer.Segment{Start: moment(start)}
+	if end != "" {
+		s.End = moment(end)
+		s.Duration = s.End.Sub(s.Start)
+	}
+	return s
+}
+
+func fixtures() []*timer.NamedTimer {
+	return []*timer.NamedTimer{
+		{
+			Name: "running",
+			Ticks: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-03-11T09:00:00Z", "2025-03-11T09:30:00Z"),
+					segment("2025-03-11T10:00:00Z", ""),
+				},
+			},
+		},
+		{
+			Name: "stopped, with comma",
+			Ticks: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-03-11T08:00:00Z", "2025-03-11T08:01:30Z"),
+				},
+			},
+		},
+		{
+			Name: "unused",
+			Ticks: &timer.Timer{},
+		},
+	}
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		err := os.WriteFile(path, got, 0644)
+		if err != nil {
+			t.Fatalf("Couldn't update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Couldn't read golden file: %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("Output didn't match golden file %v:\n\nwanted\n%s\n\ngot\n%s", path, want, got)
+	}
+}
+
+func TestWriteRecords(t *testing.T) {
+	np := timer.FixedNowProvider{Moment: moment("2025-03-11T10:15:00Z")}
+	records := output.NewRecords(fixtures(), np)
+
+	for _, format := range []string{output.JSON, output.YAML, output.CSV} {
+		t.Run(format, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			err := output.WriteRecords(buffer, format, records)
+			if err != nil {
+				t.Fatalf("WriteRecords returned an error: %v", err)
+			}
+			golden(t, "list." + format, buffer.Bytes())
+		})
+	}
+}
+
+func TestWriteRecord(t *testing.T) {
+	np := timer.FixedNowProvider{Moment: moment("2025-03-11T10:15:00Z")}
+	record := output.NewRecord("running", fixtures()[0].Ticks, np)
+
+	for _, format := range []string{output.JSON, output.YAML, output.CSV} {
+		t.Run(format, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			err := output.WriteRecord(buffer, format, record)
+			if err != nil {
+				t.Fatalf("WriteRecord returned an error: %v", err)
+			}
+			golden(t, "show." + format, buffer.Bytes())
+		})
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range output.Formats {
+		err := output.CheckFormat(format)
+		if err != nil {
+			t.Errorf("CheckFormat rejected a known format %v: %v", format, err)
+		}
+	}
+
+	err := output.CheckFormat("xml")
+	if err == nil {
+		t.Errorf("CheckFormat didn't reject an unknown format")
+	}
+}
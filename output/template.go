@@ -0,0 +1,127 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs are the helpers available to `--format` templates in addition to the text/template builtins.
+var TemplateFuncs = template.FuncMap{
+	"round":	round,
+	"truncate":	truncate,
+	"humanize":	humanize,
+	"clock":	clock,
+	"seconds":	func(d time.Duration) float64 { return d.Seconds() },
+	"minutes":	func(d time.Duration) float64 { return d.Minutes() },
+	"hours":	func(d time.Duration) float64 { return d.Hours() },
+}
+
+func parseUnit(unit string) (time.Duration, error) {
+	d, err := time.ParseDuration(unit)
+	if err != nil {
+		return 0, fmt.Errorf("Couldn't parse rounding unit %v: %v", unit, err)
+	}
+	return d, nil
+}
+
+// round rounds a duration to a multiple of unit, given as a duration string like "1s" or "15m".
+func round(unit string, d time.Duration) (time.Duration, error) {
+	m, err := parseUnit(unit)
+	if err != nil {
+		return 0, err
+	}
+	return d.Round(m), nil
+}
+
+func truncate(unit string, d time.Duration) (time.Duration, error) {
+	m, err := parseUnit(unit)
+	if err != nil {
+		return 0, err
+	}
+	return d.Truncate(m), nil
+}
+
+// humanize renders a duration in words, like "1 hour 2 minutes", dropping anything below a second.
+func humanize(d time.Duration) string {
+	if d < 0 {
+		return "-" + humanize(-d)
+	}
+
+	d = d.Truncate(time.Second)
+	if d == 0 {
+		return "0 seconds"
+	}
+
+	units := []struct {
+		name	string
+		size	time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	}
+
+	parts := make([]string, 0, len(units))
+	for _, unit := range units {
+		count := d / unit.size
+		d -= count * unit.size
+		if count == 0 {
+			continue
+		}
+
+		part := fmt.Sprintf("%d %s", count, unit.name)
+		if count != 1 {
+			part += "s"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// clock renders a duration as hours, minutes and seconds, like "01:02:03".
+func clock(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	d = d.Truncate(time.Second)
+	hours := d / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	seconds := (d % time.Minute) / time.Second
+	return fmt.Sprintf("%s%02d:%02d:%02d", sign, hours, minutes, seconds)
+}
+
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse format template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// WriteTemplate renders each record through the template, putting each one on its own line.
+func WriteTemplate(w io.Writer, tmpl *template.Template, records []Record) error {
+	for _, record := range records {
+		builder := new(strings.Builder)
+		err := tmpl.Execute(builder, record)
+		if err != nil {
+			return fmt.Errorf("Couldn't render format template for %v: %v", record.Name, err)
+		}
+
+		text := builder.String()
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		_, err = io.WriteString(w, text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dusktreader/gowatch/output"
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	np := timer.FixedNowProvider{Moment: moment("2025-03-11T10:15:00Z")}
+	records := output.NewRecords(fixtures(), np)
+
+	cases := map[string]string{
+		"{{.Name}} {{.Elapsed}}": "running 45m0s\nstopped, with comma 1m30s\nunused 0s\n",
+		"{{.Name}}: {{.Elapsed | round \"1m\"}}\n": "running: 45m0s\nstopped, with comma: 2m0s\nunused: 0s\n",
+		"{{if .Running}}{{.Name}} {{clock .Elapsed}}{{end}}": "running 00:45:00\n\n\n",
+		"{{humanize .Elapsed}}": "45 minutes\n1 minute 30 seconds\n0 seconds\n",
+		"{{printf \"%.1f\" (minutes .Elapsed)}}": "45.0\n1.5\n0.0\n",
+	}
+
+	for text, want := range cases {
+		tmpl, err := output.ParseTemplate(text)
+		if err != nil {
+			t.Fatalf("ParseTemplate returned an error for %v: %v", text, err)
+		}
+
+		buffer := new(bytes.Buffer)
+		err = output.WriteTemplate(buffer, tmpl, records)
+		if err != nil {
+			t.Fatalf("WriteTemplate returned an error for %v: %v", text, err)
+		}
+
+		got := buffer.String()
+		if got != want {
+			t.Errorf("WriteTemplate rendered %v wrong: wanted %q, got %q", text, want, got)
+		}
+	}
+}
+
+func TestWriteTemplate_Laps(t *testing.T) {
+	ticks := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T09:00:00Z", ""),
+		},
+	}
+	_, _ = ticks.Lap("first", timer.FixedNowProvider{Moment: moment("2025-03-11T09:02:00Z")})
+	_, _ = ticks.Lap("", timer.FixedNowProvider{Moment: moment("2025-03-11T09:05:00Z")})
+
+	np := timer.FixedNowProvider{Moment: moment("2025-03-11T09:06:00Z")}
+	records := []output.Record{output.NewRecord("lapped", ticks, np)}
+
+	tmpl, err := output.ParseTemplate("{{range $i, $lap := .Laps}}{{$i}}:{{$lap.Name}}:{{$lap.Split}}:{{$lap.Elapsed}} {{end}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate returned an error: %v", err)
+	}
+
+	buffer := new(bytes.Buffer)
+	err = output.WriteTemplate(buffer, tmpl, records)
+	if err != nil {
+		t.Fatalf("WriteTemplate returned an error: %v", err)
+	}
+
+	want := "0:first:2m0s:2m0s 1::3m0s:5m0s \n"
+	if buffer.String() != want {
+		t.Errorf("WriteTemplate rendered laps wrong: wanted %q, got %q", want, buffer.String())
+	}
+
+	golden(t, "laps.json", func() []byte {
+		buffer := new(bytes.Buffer)
+		err := output.WriteRecord(buffer, output.JSON, records[0])
+		if err != nil {
+			t.Fatalf("WriteRecord returned an error: %v", err)
+		}
+		return buffer.Bytes()
+	}())
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	_, err := output.ParseTemplate("{{.Name")
+	if err == nil {
+		t.Errorf("ParseTemplate didn't return an error on an invalid template")
+	}
+}
+
+func TestWriteTemplate_BadUnit(t *testing.T) {
+	tmpl, err := output.ParseTemplate("{{round \"fortnight\" .Elapsed}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate returned an error: %v", err)
+	}
+
+	err = output.WriteTemplate(new(bytes.Buffer), tmpl, []output.Record{{Name: "bad"}})
+	if err == nil {
+		t.Errorf("WriteTemplate didn't return an error for a bad rounding unit")
+	}
+}
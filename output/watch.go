@@ -0,0 +1,101 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+// Watcher redraws a set of timers every time it receives a tick. On a terminal, each frame replaces the one
+// before it. Otherwise, frames are written one after the other so the output can be piped or logged.
+type Watcher struct {
+	Out			io.Writer
+	TTY			bool
+	Precision	time.Duration
+	Load		func() ([]*timer.NamedTimer, error)
+	Now			timer.NowProvider
+
+	lines		int
+}
+
+// Run draws a frame immediately and then once per tick until the context is cancelled or ticks is closed.
+func (w *Watcher) Run(ctx context.Context, ticks <-chan time.Time) error {
+	err := w.draw()
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-ticks:
+			if !ok {
+				return nil
+			}
+			err = w.draw()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Watcher) frame() ([]string, error) {
+	nts, err := w.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	precision := w.Precision
+	if precision <= 0 {
+		precision = time.Millisecond
+	}
+
+	maxWidth := 0
+	for _, nt := range nts {
+		maxWidth = max(maxWidth, len(nt.Name))
+	}
+
+	lines := make([]string, 0, len(nts))
+	for _, nt := range nts {
+		elapsed := nt.Ticks.Elapsed(w.Now).Round(precision)
+
+		state := "stopped"
+		if nt.Ticks.IsRunning() {
+			state = "running"
+		}
+
+		line := fmt.Sprintf("%*s: %s [%s]", maxWidth, nt.Name, elapsed, state)
+		if nt.Ticks.IsCountdown() {
+			line += " " + nt.Ticks.RoundedRemainingString(precision, w.Now)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func (w *Watcher) draw() error {
+	lines, err := w.frame()
+	if err != nil {
+		return err
+	}
+
+	builder := new(strings.Builder)
+	if w.TTY && w.lines > 0 {
+		// Move back to the start of the previous frame and clear everything below it.
+		fmt.Fprintf(builder, "\x1b[%dF\x1b[J", w.lines)
+	}
+	for _, line := range lines {
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	w.lines = len(lines)
+
+	_, err = io.WriteString(w.Out, builder.String())
+	return err
+}
@@ -0,0 +1,129 @@
+package output_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/output"
+	"github.com/dusktreader/gowatch/timer"
+)
+
+type fakeClock struct {
+	moment	time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.moment
+}
+
+func runWatcher(t *testing.T, tty bool) string {
+	// The clock advances by a second before every frame so the test doesn't race with the watcher.
+	clock := &fakeClock{moment: moment("2025-03-11T10:14:59Z")}
+	buffer := new(bytes.Buffer)
+	watcher := &output.Watcher{
+		Out: buffer,
+		TTY: tty,
+		Precision: time.Second,
+		Load: func() ([]*timer.NamedTimer, error) {
+			clock.moment = clock.moment.Add(time.Second)
+			return fixtures()[:2], nil
+		},
+		Now: clock,
+	}
+
+	ticks := make(chan time.Time)
+	done := make(chan error)
+	go func() {
+		done <- watcher.Run(context.Background(), ticks)
+	}()
+
+	for range 2 {
+		ticks <- time.Time{}
+	}
+	close(ticks)
+
+	err := <-done
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	return buffer.String()
+}
+
+func TestWatcher_Lines(t *testing.T) {
+	want := "" +
+		"            running: 45m0s [running]\n" +
+		"stopped, with comma: 1m30s [stopped]\n" +
+		"            running: 45m1s [running]\n" +
+		"stopped, with comma: 1m30s [stopped]\n" +
+		"            running: 45m2s [running]\n" +
+		"stopped, with comma: 1m30s [stopped]\n"
+
+	got := runWatcher(t, false)
+	if got != want {
+		t.Errorf("Watcher wrote the wrong lines:\n\nwanted\n%s\n\ngot\n%s", want, got)
+	}
+}
+
+func TestWatcher_TTY(t *testing.T) {
+	want := "" +
+		"            running: 45m0s [running]\n" +
+		"stopped, with comma: 1m30s [stopped]\n" +
+		"\x1b[2F\x1b[J" +
+		"            running: 45m1s [running]\n" +
+		"stopped, with comma: 1m30s [stopped]\n" +
+		"\x1b[2F\x1b[J" +
+		"            running: 45m2s [running]\n" +
+		"stopped, with comma: 1m30s [stopped]\n"
+
+	got := runWatcher(t, true)
+	if got != want {
+		t.Errorf("Watcher didn't redraw in place:\n\nwanted\n%q\n\ngot\n%q", want, got)
+	}
+}
+
+func TestWatcher_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher := &output.Watcher{
+		Out: new(bytes.Buffer),
+		Load: func() ([]*timer.NamedTimer, error) {
+			return fixtures(), nil
+		},
+		Now: timer.FixedNowProvider{Moment: moment("2025-03-11T10:15:00Z")},
+	}
+
+	cancel()
+	err := watcher.Run(ctx, make(chan time.Time))
+	if err != nil {
+		t.Errorf("Run returned an error after cancellation: %v", err)
+	}
+}
+
+func TestWatcher_CountdownDue(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	watcher := &output.Watcher{
+		Out: buffer,
+		Precision: time.Second,
+		Load: func() ([]*timer.NamedTimer, error) {
+			ticks := &timer.Timer{
+				Segments: []timer.Segment{{Start: moment("2025-03-11T10:00:00Z")}},
+				Target: 15 * time.Minute,
+			}
+			return []*timer.NamedTimer{{Name: "due", Ticks: ticks}}, nil
+		},
+		Now: &fakeClock{moment: moment("2025-03-11T10:15:00Z")},
+	}
+
+	ticks := make(chan time.Time)
+	close(ticks)
+	err := watcher.Run(context.Background(), ticks)
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	want := "due: 15m0s [running] 0s remaining\n"
+	if got := buffer.String(); got != want {
+		t.Errorf("Watcher wrote the wrong line for a countdown that is due: wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,334 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dusktreader/gowatch/output"
+	"github.com/dusktreader/gowatch/timer"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	BY_DAY		= "day"
+	BY_WEEK		= "week"
+	BY_MONTH	= "month"
+	BY_TIMER	= "timer"
+	BY_TAG		= "tag"
+)
+
+var Groupings = []string{BY_DAY, BY_WEEK, BY_MONTH, BY_TIMER, BY_TAG}
+
+const UNTAGGED = "(untagged)"
+
+// Interval is a stretch of recorded time from one timer, clipped to the range of the report. Manual
+// adjustments are intervals that start and end at the moment they were made and carry their correction,
+// which may be negative, in Adjustment.
+type Interval struct {
+	Timer		string
+	Tags		[]string
+	Start		time.Time
+	End			time.Time
+	Adjustment	time.Duration
+	Manual		bool
+}
+
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start) + i.Adjustment
+}
+
+type Options struct {
+	Since		time.Time
+	Until		time.Time
+	By			string
+	Location	*time.Location
+	Now			timer.NowProvider
+}
+
+// Row holds the time recorded for one key. Manual is the part of Total that was entered by hand.
+type Row struct {
+	Key		string			`json:"key"`
+	Total	time.Duration	`json:"total_ns"`
+	Manual	time.Duration	`json:"manual_ns"`
+	Percent	float64			`json:"percent"`
+}
+
+type Report struct {
+	By		string			`json:"by"`
+	Rows	[]Row			`json:"rows"`
+	Total	time.Duration	`json:"total_ns"`
+	Manual	time.Duration	`json:"manual_ns"`
+}
+
+// Intervals collects the recorded segments of the timers, clipping them to [since, until). Running
+// segments end at the current time. A zero since or until leaves that side of the range open.
+func Intervals(nts []*timer.NamedTimer, since time.Time, until time.Time, nowProvider timer.NowProvider) []Interval {
+	now := nowProvider.Now()
+	intervals := make([]Interval, 0)
+	for _, nt := range nts {
+		for i := range nt.Ticks.Segments {
+			segment, ok := nt.Ticks.Segments[i].Clip(since, until, now)
+			if !ok {
+				continue
+			}
+
+			interval := Interval{
+				Timer: nt.Name,
+				Tags: nt.Ticks.Tags,
+				Start: segment.Start,
+				End: segment.Start.Add(segment.Duration),
+				Manual: segment.IsManual(),
+			}
+			if segment.IsAdjustment() {
+				interval.End = segment.Start
+				interval.Adjustment = segment.Duration
+			}
+			intervals = append(intervals, interval)
+		}
+	}
+	return intervals
+}
+
+func periodStart(moment time.Time, by string) time.Time {
+	midnight := time.Date(moment.Year(), moment.Month(), moment.Day(), 0, 0, 0, 0, moment.Location())
+	switch by {
+	case BY_WEEK:
+		// ISO weeks start on Monday.
+		offset := (int(midnight.Weekday()) + 6) % 7
+		return midnight.AddDate(0, 0, -offset)
+	case BY_MONTH:
+		return time.Date(moment.Year(), moment.Month(), 1, 0, 0, 0, 0, moment.Location())
+	}
+	return midnight
+}
+
+func nextPeriod(start time.Time, by string) time.Time {
+	switch by {
+	case BY_WEEK:
+		return start.AddDate(0, 0, 7)
+	case BY_MONTH:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func periodKey(start time.Time, by string) string {
+	switch by {
+	case BY_WEEK:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case BY_MONTH:
+		return start.Format("2006-01")
+	}
+	return start.Format("2006-01-02")
+}
+
+// SplitPeriods cuts an interval at every day, week or month boundary it crosses in the given location. An
+// adjustment counts towards the period it was made in.
+func SplitPeriods(interval Interval, by string, location *time.Location) map[string]time.Duration {
+	totals := map[string]time.Duration{}
+	if interval.Adjustment != 0 {
+		totals[periodKey(periodStart(interval.Start.In(location), by), by)] += interval.Adjustment
+	}
+	current := interval.Start.In(location)
+	end := interval.End.In(location)
+	for current.Before(end) {
+		start := periodStart(current, by)
+		next := nextPeriod(start, by)
+		if next.After(end) {
+			next = end
+		}
+		totals[periodKey(start, by)] += next.Sub(current)
+		current = next
+	}
+	return totals
+}
+
+func Build(nts []*timer.NamedTimer, options Options) (*Report, error) {
+	if !slices.Contains(Groupings, options.By) {
+		return nil, fmt.Errorf("Unknown report grouping %v: expected one of %v", options.By, Groupings)
+	}
+	if !options.Since.IsZero() && !options.Until.IsZero() && !options.Until.After(options.Since) {
+		return nil, fmt.Errorf("The end of the report range must be after its start")
+	}
+
+	location := options.Location
+	if location == nil {
+		location = time.Local
+	}
+	nowProvider := options.Now
+	if nowProvider == nil {
+		nowProvider = timer.RealNowProvider{}
+	}
+
+	totals := map[string]time.Duration{}
+	manuals := map[string]time.Duration{}
+	var total time.Duration
+	var manual time.Duration
+	for _, interval := range Intervals(nts, options.Since, options.Until, nowProvider) {
+		keyed := map[string]time.Duration{}
+		switch options.By {
+		case BY_TIMER:
+			keyed[interval.Timer] = interval.Duration()
+		case BY_TAG:
+			if len(interval.Tags) == 0 {
+				keyed[UNTAGGED] = interval.Duration()
+			}
+			for _, tag := range interval.Tags {
+				keyed[tag] = interval.Duration()
+			}
+		default:
+			keyed = SplitPeriods(interval, options.By, location)
+		}
+
+		total += interval.Duration()
+		if interval.Manual {
+			manual += interval.Duration()
+		}
+		for key, duration := range keyed {
+			totals[key] += duration
+			if interval.Manual {
+				manuals[key] += duration
+			}
+		}
+	}
+
+	report := &Report{
+		By: options.By,
+		Rows: make([]Row, 0, len(totals)),
+		Total: total,
+		Manual: manual,
+	}
+	for key, duration := range totals {
+		row := Row{Key: key, Total: duration, Manual: manuals[key]}
+		if total > 0 {
+			row.Percent = 100 * float64(duration) / float64(total)
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		return report.Rows[i].Key < report.Rows[j].Key
+	})
+
+	return report, nil
+}
+
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case output.TEXT:
+		return r.writeTable(w)
+	case output.JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case output.YAML:
+		return r.writeYAML(w)
+	case output.CSV:
+		return r.writeCSV(w)
+	}
+	return fmt.Errorf("Can't write report as %v", format)
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+func (r *Report) writeTable(w io.Writer) error {
+	width := len(r.By)
+	for _, row := range r.Rows {
+		width = max(width, len(row.Key))
+	}
+	width = max(width, len("total"))
+
+	// The manual column only appears when some of the time was entered by hand.
+	hasManual := r.Manual != 0 || slices.ContainsFunc(r.Rows, func(row Row) bool { return row.Manual != 0 })
+
+	header := fmt.Sprintf("%-*s  %12s  %7s", width, r.By, "time", "percent")
+	if hasManual {
+		header += fmt.Sprintf("  %12s", "manual")
+	}
+	lines := []string{header}
+	for _, row := range r.Rows {
+		line := fmt.Sprintf("%-*s  %12s  %6.1f%%", width, row.Key, formatDuration(row.Total), row.Percent)
+		if hasManual {
+			line += fmt.Sprintf("  %12s", formatDuration(row.Manual))
+		}
+		lines = append(lines, line)
+	}
+	footer := fmt.Sprintf("%-*s  %12s", width, "total", formatDuration(r.Total))
+	if hasManual {
+		footer += fmt.Sprintf("  %7s  %12s", "", formatDuration(r.Manual))
+	}
+	lines = append(lines, footer)
+
+	for _, line := range lines {
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Report) writeYAML(w io.Writer) error {
+	type yamlRow struct {
+		Key		string	`yaml:"key"`
+		Total	int64	`yaml:"total_ns"`
+		Manual	int64	`yaml:"manual_ns"`
+		Percent	float64	`yaml:"percent"`
+	}
+	type yamlReport struct {
+		By		string		`yaml:"by"`
+		Rows	[]yamlRow	`yaml:"rows"`
+		Total	int64		`yaml:"total_ns"`
+		Manual	int64		`yaml:"manual_ns"`
+	}
+
+	data := yamlReport{
+		By: r.By,
+		Rows: make([]yamlRow, 0, len(r.Rows)),
+		Total: int64(r.Total),
+		Manual: int64(r.Manual),
+	}
+	for _, row := range r.Rows {
+		data.Rows = append(
+			data.Rows,
+			yamlRow{Key: row.Key, Total: int64(row.Total), Manual: int64(row.Manual), Percent: row.Percent},
+		)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(data)
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func (r *Report) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{r.By, "total_ns", "manual_ns", "percent"})
+	if err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		err = writer.Write([]string{
+			row.Key,
+			strconv.FormatInt(int64(row.Total), 10),
+			strconv.FormatInt(int64(row.Manual), 10),
+			strconv.FormatFloat(row.Percent, 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,280 @@
+package report_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/output"
+	"github.com/dusktreader/gowatch/report"
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func moment(m string) time.Time {
+	t, err := time.Parse(time.RFC3339, m)
+	if err != nil {
+		panic("Couldn't parse time: " + m)
+	}
+	return t
+}
+
+func segment(start string, end string) timer.Segment {
+	s := timer.Segment{Start: moment(start)}
+	if end != "" {
+		s.End = moment(end)
+		s.Duration = s.End.Sub(s.Start)
+	}
+	return s
+}
+
+func span(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		panic("Couldn't parse duration: " + s)
+	}
+	return d
+}
+
+func fixtures() []*timer.NamedTimer {
+	return []*timer.NamedTimer{
+		{
+			Name: "late",
+			Ticks: &timer.Timer{
+				Segments: []timer.Segment{
+					// Crosses midnight, the end of a week (Sunday) and the end of a month.
+					segment("2025-08-31T22:00:00Z", "2025-09-01T02:00:00Z"),
+				},
+				Tags: []string{"client-a", "ops"},
+			},
+		},
+		{
+			Name: "day",
+			Ticks: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-09-01T09:00:00Z", "2025-09-01T11:00:00Z"),
+					segment("2025-09-02T09:00:00Z", ""),
+				},
+				Tags: []string{"client-a"},
+			},
+		},
+		{
+			Name: "untagged",
+			Ticks: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-08-30T10:00:00Z", "2025-08-30T11:00:00Z"),
+				},
+			},
+		},
+	}
+}
+
+func build(t *testing.T, options report.Options) *report.Report {
+	options.Location = time.UTC
+	options.Now = timer.FixedNowProvider{Moment: moment("2025-09-02T10:00:00Z")}
+	r, err := report.Build(fixtures(), options)
+	if err != nil {
+		t.Fatalf("Build returned an error: %v", err)
+	}
+	return r
+}
+
+func totals(r *report.Report) map[string]time.Duration {
+	totals := map[string]time.Duration{}
+	for _, row := range r.Rows {
+		totals[row.Key] = row.Total
+	}
+	return totals
+}
+
+func TestBuild_ByPeriod(t *testing.T) {
+	cases := map[string]map[string]time.Duration{
+		report.BY_DAY: {
+			"2025-08-30": span("1h"),
+			"2025-08-31": span("2h"),
+			"2025-09-01": span("4h"),
+			"2025-09-02": span("1h"),
+		},
+		report.BY_WEEK: {
+			"2025-W35": span("3h"),
+			"2025-W36": span("5h"),
+		},
+		report.BY_MONTH: {
+			"2025-08": span("3h"),
+			"2025-09": span("5h"),
+		},
+	}
+
+	for by, want := range cases {
+		r := build(t, report.Options{By: by})
+		got := totals(r)
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("Build didn't split intervals by %v correctly: wanted %v, got %v", by, want, got)
+		}
+		if r.Total != span("8h") {
+			t.Errorf("Build computed the wrong total by %v: wanted %v, got %v", by, span("8h"), r.Total)
+		}
+	}
+}
+
+func TestBuild_ByTimerAndTag(t *testing.T) {
+	r := build(t, report.Options{By: report.BY_TIMER})
+	want := map[string]time.Duration{
+		"late": span("4h"),
+		"day": span("3h"),
+		"untagged": span("1h"),
+	}
+	if !reflect.DeepEqual(want, totals(r)) {
+		t.Errorf("Build grouped by timer wrong: wanted %v, got %v", want, totals(r))
+	}
+	if r.Rows[0].Key != "day" || r.Rows[0].Percent != 37.5 {
+		t.Errorf("Build computed the wrong percentage: got %v", r.Rows[0])
+	}
+
+	r = build(t, report.Options{By: report.BY_TAG})
+	want = map[string]time.Duration{
+		"client-a": span("7h"),
+		"ops": span("4h"),
+		report.UNTAGGED: span("1h"),
+	}
+	if !reflect.DeepEqual(want, totals(r)) {
+		t.Errorf("Build grouped by tag wrong: wanted %v, got %v", want, totals(r))
+	}
+}
+
+func TestBuild_Range(t *testing.T) {
+	r := build(t, report.Options{
+		By: report.BY_DAY,
+		Since: moment("2025-08-31T23:00:00Z"),
+		Until: moment("2025-09-01T10:00:00Z"),
+	})
+	want := map[string]time.Duration{
+		"2025-08-31": span("1h"),
+		"2025-09-01": span("3h"),
+	}
+	if !reflect.DeepEqual(want, totals(r)) {
+		t.Errorf("Build didn't clip intervals to the range: wanted %v, got %v", want, totals(r))
+	}
+
+	_, err := report.Build(fixtures(), report.Options{By: "fortnight"})
+	if err == nil {
+		t.Errorf("Build didn't return an error for an unknown grouping")
+	}
+
+	_, err = report.Build(fixtures(), report.Options{
+		By: report.BY_DAY,
+		Since: moment("2025-09-01T00:00:00Z"),
+		Until: moment("2025-08-01T00:00:00Z"),
+	})
+	if err == nil {
+		t.Errorf("Build didn't return an error for an inverted range")
+	}
+}
+
+func TestBuild_Location(t *testing.T) {
+	options := report.Options{
+		By: report.BY_DAY,
+		Location: time.FixedZone("east", 3 * 60 * 60),
+		Now: timer.FixedNowProvider{Moment: moment("2025-09-02T10:00:00Z")},
+	}
+	r, err := report.Build(fixtures()[:1], options)
+	if err != nil {
+		t.Fatalf("Build returned an error: %v", err)
+	}
+
+	want := map[string]time.Duration{"2025-09-01": span("4h")}
+	if !reflect.DeepEqual(want, totals(r)) {
+		t.Errorf("Build didn't split days in the report's location: wanted %v, got %v", want, totals(r))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	r := build(t, report.Options{By: report.BY_MONTH})
+
+	buffer := new(bytes.Buffer)
+	err := r.Write(buffer, output.TEXT)
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	want := "" +
+		"month            time  percent\n" +
+		"2025-08        3h0m0s    37.5%\n" +
+		"2025-09        5h0m0s    62.5%\n" +
+		"total          8h0m0s\n"
+	if buffer.String() != want {
+		t.Errorf("Write rendered the wrong table:\n\nwanted\n%s\n\ngot\n%s", want, buffer.String())
+	}
+
+	buffer.Reset()
+	err = r.Write(buffer, output.CSV)
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	want = "month,total_ns,manual_ns,percent\n2025-08,10800000000000,0,37.50\n2025-09,18000000000000,0,62.50\n"
+	if buffer.String() != want {
+		t.Errorf("Write rendered the wrong csv:\n\nwanted\n%s\n\ngot\n%s", want, buffer.String())
+	}
+}
+
+func TestBuild_Manual(t *testing.T) {
+	nts := fixtures()
+	nts[2].Ticks.Segments = append(
+		nts[2].Ticks.Segments,
+		timer.Segment{
+			Start: moment("2025-09-01T08:00:00Z"),
+			End: moment("2025-09-01T08:30:00Z"),
+			Duration: span("30m"),
+			Kind: timer.MANUAL,
+		},
+		timer.Segment{
+			Start: moment("2025-09-01T12:00:00Z"),
+			End: moment("2025-09-01T12:00:00Z"),
+			Duration: -span("15m"),
+			Kind: timer.MANUAL,
+		},
+	)
+
+	r, err := report.Build(nts, report.Options{
+		By: report.BY_TIMER,
+		Location: time.UTC,
+		Now: timer.FixedNowProvider{Moment: moment("2025-09-02T10:00:00Z")},
+	})
+	if err != nil {
+		t.Fatalf("Build returned an error: %v", err)
+	}
+
+	if r.Manual != span("15m") || r.Total != span("8h15m") {
+		t.Errorf("Build didn't count manual time: wanted 15m0s of 8h15m0s, got %v of %v", r.Manual, r.Total)
+	}
+	if totals(r)["untagged"] != span("1h15m") {
+		t.Errorf("Build didn't apply the adjustment to its timer: wanted %v, got %v", span("1h15m"), totals(r)["untagged"])
+	}
+
+	buffer := new(bytes.Buffer)
+	err = r.Write(buffer, output.TEXT)
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	want := "" +
+		"timer             time  percent        manual\n" +
+		"day             3h0m0s    36.4%            0s\n" +
+		"late            4h0m0s    48.5%            0s\n" +
+		"untagged       1h15m0s    15.2%         15m0s\n" +
+		"total          8h15m0s                  15m0s\n"
+	if buffer.String() != want {
+		t.Errorf("Write rendered the wrong table:\n\nwanted\n%s\n\ngot\n%s", want, buffer.String())
+	}
+
+	r, err = report.Build(nts, report.Options{
+		By: report.BY_DAY,
+		Since: moment("2025-09-01T10:00:00Z"),
+		Location: time.UTC,
+		Now: timer.FixedNowProvider{Moment: moment("2025-09-02T10:00:00Z")},
+	})
+	if err != nil {
+		t.Fatalf("Build returned an error: %v", err)
+	}
+	if r.Manual != -span("15m") {
+		t.Errorf("Build didn't clip manual time to the range: wanted %v, got %v", -span("15m"), r.Manual)
+	}
+}
@@ -0,0 +1,104 @@
+package timer
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// MANUAL marks segments that were entered by hand instead of measured by starting and stopping a timer.
+const MANUAL = "manual"
+
+func (s *Segment) IsManual() bool {
+	return s.Kind == MANUAL
+}
+
+// IsAdjustment reports whether the segment is a manual correction of the total that covers no span of time.
+// Its duration may be negative.
+func (s *Segment) IsAdjustment() bool {
+	return s.IsManual() && s.Start.Equal(s.End)
+}
+
+// spanEnd is the end of the time a segment covers. Running segments are taken to run forever.
+func (s *Segment) spanEnd() time.Time {
+	if s.IsOpen() {
+		return time.Unix(1 << 62, 0)
+	}
+	return s.End
+}
+
+// Overlaps reports whether two segments cover any of the same time. Adjustments cover no time, so they never
+// overlap anything.
+func (s *Segment) Overlaps(other *Segment) bool {
+	if s.IsAdjustment() || other.IsAdjustment() {
+		return false
+	}
+	return s.Start.Before(other.spanEnd()) && other.Start.Before(s.spanEnd())
+}
+
+// insertSegment keeps the segments in start order while keeping a running segment last.
+func (t *Timer) insertSegment(segment Segment) {
+	i := len(t.Segments)
+	for i > 0 && t.Segments[i - 1].Start.After(segment.Start) {
+		i--
+	}
+	if i == len(t.Segments) && t.IsRunning() {
+		i--
+	}
+	t.Segments = slices.Insert(t.Segments, i, segment)
+}
+
+// Adjust records a manual correction of d, which may be negative, without covering any span of time. It
+// refuses to take the elapsed time below zero.
+func (t *Timer) Adjust(d time.Duration, note string, nowProviderArg ...NowProvider) error {
+	moment := now(nowProviderArg)
+	if t.Elapsed(FixedNowProvider{Moment: moment}) + d < 0 {
+		return fmt.Errorf("Can't take the timer below zero")
+	}
+
+	t.insertSegment(Segment{Start: moment, End: moment, Duration: d, Note: note, Kind: MANUAL})
+	return nil
+}
+
+// SetElapsed records the manual correction that brings the elapsed time to exactly d.
+func (t *Timer) SetElapsed(d time.Duration, note string, nowProviderArg ...NowProvider) error {
+	if d < 0 {
+		return fmt.Errorf("Can't set the timer below zero")
+	}
+
+	moment := now(nowProviderArg)
+	return t.Adjust(d - t.Elapsed(FixedNowProvider{Moment: moment}), note, FixedNowProvider{Moment: moment})
+}
+
+// AddInterval records a backdated manual segment from start to end. It may not end in the future or overlap
+// any time the timer has already recorded.
+func (t *Timer) AddInterval(start time.Time, end time.Time, note string, nowProviderArg ...NowProvider) error {
+	if !end.After(start) {
+		return fmt.Errorf("The end of the interval must be after its start")
+	}
+	if end.After(now(nowProviderArg)) {
+		return fmt.Errorf("The interval must not end in the future")
+	}
+
+	interval := Segment{Start: start, End: end, Duration: end.Sub(start), Note: note, Kind: MANUAL}
+	for i := range t.Segments {
+		existing := &t.Segments[i]
+		if interval.Overlaps(existing) {
+			return fmt.Errorf("The interval overlaps recorded time %s", existing)
+		}
+	}
+
+	t.insertSegment(interval)
+	return nil
+}
+
+// ManualElapsed returns the part of the elapsed time that was entered by hand.
+func (t *Timer) ManualElapsed() time.Duration {
+	var elapsed time.Duration
+	for i := range t.Segments {
+		if t.Segments[i].IsManual() {
+			elapsed += t.Segments[i].Duration
+		}
+	}
+	return elapsed
+}
@@ -0,0 +1,117 @@
+package timer_test
+
+import (
+	"testing"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestAdjust(t *testing.T) {
+	ticks := &timer.Timer{Segments: []timer.Segment{segment("2025-09-01T09:00:00Z", "2025-09-01T10:00:00Z")}}
+	np := freeze(t, "2025-09-01T12:00:00Z")
+
+	err := ticks.Adjust(span("15m"), "forgot to start", np)
+	if err != nil {
+		t.Fatalf("Adjust returned an error: %v", err)
+	}
+	if ticks.Elapsed(np) != span("1h15m") {
+		t.Errorf("Adjust didn't add to the elapsed time: wanted %v, got %v", span("1h15m"), ticks.Elapsed(np))
+	}
+
+	adjustment := ticks.Segments[1]
+	if !adjustment.IsAdjustment() || adjustment.Note != "forgot to start" {
+		t.Errorf("Adjust didn't record a manual adjustment: got %v", adjustment)
+	}
+	if ticks.ManualElapsed() != span("15m") {
+		t.Errorf("ManualElapsed didn't count the adjustment: wanted %v, got %v", span("15m"), ticks.ManualElapsed())
+	}
+
+	err = ticks.Adjust(-span("30m"), "", np)
+	if err != nil {
+		t.Fatalf("Adjust returned an error: %v", err)
+	}
+	if ticks.Elapsed(np) != span("45m") {
+		t.Errorf("Adjust didn't subtract from the elapsed time: wanted %v, got %v", span("45m"), ticks.Elapsed(np))
+	}
+
+	err = ticks.Adjust(-span("1h"), "", np)
+	if err == nil {
+		t.Errorf("Adjust didn't refuse to take the timer below zero")
+	}
+}
+
+func TestAdjust_KeepsRunningSegmentLast(t *testing.T) {
+	ticks := &timer.Timer{Segments: []timer.Segment{segment("2025-09-01T09:00:00Z", "")}}
+	np := freeze(t, "2025-09-01T10:00:00Z")
+
+	err := ticks.Adjust(span("10m"), "", np)
+	if err != nil {
+		t.Fatalf("Adjust returned an error: %v", err)
+	}
+	if !ticks.IsRunning() {
+		t.Errorf("Adjust stopped the running timer: got %v", ticks.Segments)
+	}
+	if ticks.Elapsed(np) != span("1h10m") {
+		t.Errorf("Adjust didn't add to the running timer: wanted %v, got %v", span("1h10m"), ticks.Elapsed(np))
+	}
+}
+
+func TestSetElapsed(t *testing.T) {
+	ticks := &timer.Timer{Segments: []timer.Segment{segment("2025-09-01T09:00:00Z", "2025-09-01T10:00:00Z")}}
+	np := freeze(t, "2025-09-01T12:00:00Z")
+
+	err := ticks.SetElapsed(span("25m"), "", np)
+	if err != nil {
+		t.Fatalf("SetElapsed returned an error: %v", err)
+	}
+	if ticks.Elapsed(np) != span("25m") {
+		t.Errorf("SetElapsed didn't set the elapsed time: wanted %v, got %v", span("25m"), ticks.Elapsed(np))
+	}
+	if ticks.ManualElapsed() != -span("35m") {
+		t.Errorf("SetElapsed didn't record the correction: wanted %v, got %v", -span("35m"), ticks.ManualElapsed())
+	}
+
+	err = ticks.SetElapsed(-span("1m"), "", np)
+	if err == nil {
+		t.Errorf("SetElapsed didn't refuse a negative time")
+	}
+}
+
+func TestAddInterval(t *testing.T) {
+	ticks := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-09-01T11:00:00Z", "2025-09-01T12:00:00Z"),
+			segment("2025-09-01T13:00:00Z", ""),
+		},
+	}
+
+	err := ticks.AddInterval(moment("2025-09-01T09:00:00Z"), moment("2025-09-01T10:30:00Z"), "standup")
+	if err != nil {
+		t.Fatalf("AddInterval returned an error: %v", err)
+	}
+
+	first := ticks.Segments[0]
+	if !first.IsManual() || first.IsAdjustment() || first.Duration != span("1h30m") {
+		t.Errorf("AddInterval didn't insert the interval in order: got %v", ticks.Segments)
+	}
+	if !ticks.IsRunning() {
+		t.Errorf("AddInterval stopped the running timer: got %v", ticks.Segments)
+	}
+
+	overlaps := [][2]string{
+		{"2025-09-01T10:00:00Z", "2025-09-01T11:00:00Z"},
+		{"2025-09-01T11:30:00Z", "2025-09-01T11:45:00Z"},
+		{"2025-09-01T14:00:00Z", "2025-09-01T15:00:00Z"},
+	}
+	for _, overlap := range overlaps {
+		err = ticks.AddInterval(moment(overlap[0]), moment(overlap[1]), "")
+		if err == nil {
+			t.Errorf("AddInterval didn't refuse overlapping interval %v", overlap)
+		}
+	}
+
+	err = ticks.AddInterval(moment("2025-09-01T12:00:00Z"), moment("2025-09-01T12:00:00Z"), "")
+	if err == nil {
+		t.Errorf("AddInterval didn't refuse an empty interval")
+	}
+}
@@ -0,0 +1,76 @@
+package timer
+
+import (
+	"fmt"
+)
+
+// StartExclusive starts the named timer and stops every other running timer at the same moment, returning
+// the timers it stopped. update, when given, can change the named timer once it has started. Nothing is
+// saved unless every timer could be started or stopped. The caller must hold the store lock, so that it can
+// check the timer under the same lock and so that readers using Snapshot never see the switch half done.
+func StartExclusive(s Store, name string, update func(t *Timer) error, nowProviderArg ...NowProvider) ([]*NamedTimer, error) {
+	nts, err := s.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{name}
+	for _, nt := range nts {
+		if nt.Name != name && nt.Ticks.IsRunning() {
+			names = append(names, nt.Name)
+		}
+	}
+
+	unlock, err := lockNames(s, names...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Every change must use the same moment, even when none was given.
+	moment := now(nowProviderArg)
+	np := FixedNowProvider{Moment: moment}
+
+	t, err := s.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	err = t.Start(np)
+	if err != nil {
+		return nil, err
+	}
+	if update != nil {
+		err = update(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paused := []*NamedTimer{}
+	for _, other := range names[1:] {
+		// The list of running timers was made before the locks were taken, so check again.
+		ticks, err := s.Load(other)
+		if err != nil {
+			return nil, err
+		}
+		if !ticks.IsRunning() {
+			continue
+		}
+		err = ticks.Stop(np)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't pause %v: %v", other, err)
+		}
+		paused = append(paused, &NamedTimer{Name: other, Ticks: ticks})
+	}
+
+	for _, nt := range paused {
+		err = s.Dump(nt.Name, nt.Ticks)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.Dump(name, t)
+	if err != nil {
+		return nil, err
+	}
+	return paused, nil
+}
@@ -0,0 +1,118 @@
+package timer_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestStartExclusive(t *testing.T) {
+	s := transferStore(t)
+	err := s.Dump("other", &timer.Timer{Segments: []timer.Segment{segment("2025-03-11T11:30:00Z", "")}})
+	if err != nil {
+		t.Fatalf("Couldn't dump timer: %v", err)
+	}
+
+	np := freeze(t, "2025-03-11T12:00:00Z")
+	paused, err := timer.StartExclusive(s, "one", func(ticks *timer.Timer) error {
+		ticks.Current().AddNote("focus")
+		return nil
+	}, np)
+	if err != nil {
+		t.Fatalf("StartExclusive returned an error: %v", err)
+	}
+
+	names := []string{}
+	for _, nt := range paused {
+		names = append(names, nt.Name)
+	}
+	if fmt.Sprint(names) != "[other running]" {
+		t.Errorf("StartExclusive didn't pause the running timers: wanted [other running], got %v", names)
+	}
+
+	one, _ := s.Load("one", true)
+	if !one.IsRunning() || !one.Current().Start.Equal(np.Moment) || one.Current().Note != "focus" {
+		t.Errorf("StartExclusive didn't start and update the timer: got %v", one.Current())
+	}
+	for _, name := range []string{"other", "running"} {
+		ticks, _ := s.Load(name, true)
+		if ticks.IsRunning() || !ticks.Ended().Equal(np.Moment) {
+			t.Errorf("StartExclusive didn't stop %v at the same moment: wanted %v, got %v", name, np.Moment, ticks.Ended())
+		}
+	}
+	two, _ := s.Load("two", true)
+	if two.Elapsed() != span("30m") {
+		t.Errorf("StartExclusive changed a stopped timer: got %v", two)
+	}
+}
+
+func TestStartExclusive_Failure(t *testing.T) {
+	s := transferStore(t)
+
+	// The running timer started at 11:00, so it can't be paused at 10:30.
+	_, err := timer.StartExclusive(s, "one", nil, freeze(t, "2025-03-11T10:30:00Z"))
+	if err == nil {
+		t.Fatalf("StartExclusive didn't error when a timer couldn't be paused")
+	}
+
+	one, _ := s.Load("one", true)
+	running, _ := s.Load("running", true)
+	if one.IsRunning() || !running.IsRunning() {
+		t.Errorf("StartExclusive saved changes after failing")
+	}
+
+	_, err = timer.StartExclusive(s, "running", nil, freeze(t, "2025-03-11T12:00:00Z"))
+	if err == nil {
+		t.Errorf("StartExclusive didn't error on a running timer")
+	}
+}
+
+func TestStartExclusive_Readers(t *testing.T) {
+	s := timer.NewFileStore(t.TempDir())
+
+	starters := 10
+	var wg sync.WaitGroup
+	errs := make(chan error, starters * 2)
+	for i := range starters {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			lock, err := s.LockStore()
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer func() { _ = lock.Unlock() }()
+
+			_, err = timer.StartExclusive(s, fmt.Sprintf("timer-%d", i), nil)
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			nts, err := timer.Snapshot(s)
+			if err != nil {
+				errs <- err
+				return
+			}
+			running := 0
+			for _, nt := range nts {
+				if nt.Ticks.IsRunning() {
+					running++
+				}
+			}
+			if running > 1 {
+				errs <- fmt.Errorf("Reader saw %v running timers", running)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Concurrent start or read failed: %v", err)
+		}
+	}
+}
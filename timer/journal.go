@@ -0,0 +1,300 @@
+package timer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const JOURNAL_FILE_NAME = "timers.jsonl"
+
+// Compaction is skipped until the journal holds at least this many stale records.
+const journalCompactThreshold = 64
+
+// JournalStore keeps every timer in a single JSON-lines file. Each update appends a record for the timer,
+// and the last record for a name wins. The journal is rewritten without stale records once they start to
+// outnumber the live ones.
+//
+// Like the FileStore, writers are expected to hold the lock from Lock while they update a timer. Each name has
+// its own lock, and every write to the journal itself is also serialized, so that an append can't be lost to
+// a concurrent compaction.
+type JournalStore struct {
+	Path	string
+}
+
+type journalRecord struct {
+	Name	string	`json:"name"`
+	Timer	*Timer	`json:"timer,omitempty"`
+	Deleted	bool	`json:"deleted,omitempty"`
+}
+
+func NewJournalStore(path string) *JournalStore {
+	return &JournalStore{Path: path}
+}
+
+func (s *JournalStore) read() (map[string]*Timer, int, error) {
+	timers := map[string]*Timer{}
+
+	file, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return timers, 0, nil
+	} else if err != nil {
+		msg := "Error opening journal"
+		slog.Error(msg, "error", err)
+		return nil, 0, fmt.Errorf(msg + ": %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	count := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64 * 1024), 64 * 1024 * 1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		record := new(journalRecord)
+		err := json.Unmarshal(line, record)
+		if err != nil {
+			slog.Warn("Skipping journal record that failed to load", "line", count + 1, "error", err)
+			continue
+		}
+		count++
+
+		if record.Deleted || record.Timer == nil {
+			delete(timers, record.Name)
+		} else {
+			timers[record.Name] = record.Timer
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		msg := "Error reading journal"
+		slog.Error(msg, "error", err)
+		return nil, 0, fmt.Errorf(msg + ": %v", err)
+	}
+	return timers, count, nil
+}
+
+// lockWrites serializes changes to the journal file. It is held only while the file is being written.
+func (s *JournalStore) lockWrites() (func(), error) {
+	lock, err := lockPath(s.Path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = lock.Unlock() }, nil
+}
+
+func (s *JournalStore) append(record journalRecord) error {
+	unlock, err := s.lockWrites()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		msg := "Error dumping timer data"
+		slog.Error(msg, "error", err)
+		return fmt.Errorf(msg + ": %v", err)
+	}
+
+	file, err := os.OpenFile(s.Path, os.O_RDWR | os.O_APPEND | os.O_CREATE, 0644)
+	if err != nil {
+		msg := "Error opening journal"
+		slog.Error(msg, "error", err)
+		return fmt.Errorf(msg + ": %v", err)
+	}
+
+	// A crash during an earlier append can leave a partial record behind. Start a fresh line so that the
+	// new record isn't glued onto it.
+	info, err := file.Stat()
+	if err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		_, err = file.ReadAt(last, info.Size() - 1)
+		if err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+
+	if err == nil {
+		_, err = file.Write(append(data, '\n'))
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		msg := "Error writing to journal"
+		slog.Error(msg, "error", err)
+		return fmt.Errorf(msg + ": %v", err)
+	}
+
+	return s.maybeCompact()
+}
+
+func (s *JournalStore) maybeCompact() error {
+	timers, count, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	stale := count - len(timers)
+	if stale < journalCompactThreshold || stale < len(timers) {
+		return nil
+	}
+
+	slog.Debug("Compacting journal", "path", s.Path, "live", len(timers), "stale", stale)
+	return s.write(timers)
+}
+
+func (s *JournalStore) write(timers map[string]*Timer) error {
+	names := make([]string, 0, len(timers))
+	for name := range timers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := make([]byte, 0)
+	for _, name := range names {
+		line, err := json.Marshal(journalRecord{Name: name, Timer: timers[name]})
+		if err != nil {
+			msg := "Error dumping timer data"
+			slog.Error(msg, "error", err)
+			return fmt.Errorf(msg + ": %v", err)
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+
+	err := writeFileAtomic(s.Path, data)
+	if err != nil {
+		msg := "Error writing journal"
+		slog.Error(msg, "error", err)
+		return fmt.Errorf(msg + ": %v", err)
+	}
+	return nil
+}
+
+func (s *JournalStore) Exists(name string) (bool, error) {
+	timers, _, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	_, ok := timers[name]
+	return ok, nil
+}
+
+func (s *JournalStore) Load(name string, mustExist ...bool) (*Timer, error) {
+	slog.Debug("Loading timer from journal", "path", s.Path, "name", name)
+
+	timers, _, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := timers[name]
+	if !ok {
+		if len(mustExist) > 0 && mustExist[0] {
+			msg := "Timer does not exist"
+			slog.Error(msg, "name", name)
+			return nil, fmt.Errorf(msg + ": %v", name)
+		}
+		return new(Timer), nil
+	}
+	return t, nil
+}
+
+func (s *JournalStore) LoadAll() ([]*NamedTimer, error) {
+	timers, _, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(timers))
+	for name := range timers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	namedTimers := make([]*NamedTimer, 0, len(names))
+	for _, name := range names {
+		namedTimers = append(namedTimers, &NamedTimer{Name: name, Ticks: timers[name]})
+	}
+	return namedTimers, nil
+}
+
+func (s *JournalStore) Dump(name string, t *Timer) error {
+	slog.Debug("Appending timer to journal", "path", s.Path, "name", name)
+	return s.append(journalRecord{Name: name, Timer: t})
+}
+
+func (s *JournalStore) Clear(name string) error {
+	ok, err := s.Exists(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		msg := "Error clearing timer data"
+		slog.Error(msg, "name", name)
+		return fmt.Errorf(msg + ": timer does not exist: %v", name)
+	}
+
+	slog.Debug("Appending deletion to journal", "path", s.Path, "name", name)
+	err = s.append(journalRecord{Name: name, Deleted: true})
+	if err != nil {
+		return err
+	}
+	removeLockFile(s.lockFilePath(name))
+	return nil
+}
+
+func (s *JournalStore) ClearAll() error {
+	unlock, err := s.lockWrites()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	timers, _, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("Truncating journal", "path", s.Path)
+	err = s.write(map[string]*Timer{})
+	if err != nil {
+		return err
+	}
+	for name := range timers {
+		removeLockFile(s.lockFilePath(name))
+	}
+	return nil
+}
+
+func (s *JournalStore) lockFilePath(name string) string {
+	return s.Path + "." + EncodeName(name) + ".lock"
+}
+
+func (s *JournalStore) Lock(name string) (Unlocker, error) {
+	return lockPath(s.lockFilePath(name))
+}
+
+func (s *JournalStore) LockStore() (Unlocker, error) {
+	return lockPath(filepath.Join(filepath.Dir(s.Path), STORE_LOCK_FILE_NAME))
+}
+
+func (s *JournalStore) LoadState() (*State, error) {
+	return loadStateFile(filepath.Dir(s.Path))
+}
+
+func (s *JournalStore) UpdateState(update func(state *State)) error {
+	return updateStateFile(filepath.Dir(s.Path), update)
+}
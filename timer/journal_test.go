@@ -0,0 +1,86 @@
+package timer_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestJournalStore_Compacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), timer.JOURNAL_FILE_NAME)
+	store := timer.NewJournalStore(path)
+
+	ticks := new(timer.Timer)
+	for i := range 200 {
+		_ = ticks.Start(freeze(t, fmt.Sprintf("2025-03-11T%02d:%02d:00Z", 14 + i / 60, i % 60)))
+		_ = ticks.Stop(freeze(t, fmt.Sprintf("2025-03-11T%02d:%02d:30Z", 14 + i / 60, i % 60)))
+		err := store.Dump("busy", ticks)
+		if err != nil {
+			t.Fatalf("Dump returned an error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Couldn't read journal: %v", err)
+	}
+	lines := bytes.Count(data, []byte("\n"))
+	if lines > 100 {
+		t.Errorf("Journal wasn't compacted: found %v records", lines)
+	}
+
+	got, err := store.Load("busy", true)
+	if err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+	if len(got.Segments) != 200 {
+		t.Errorf("Compaction lost segments: wanted 200, got %v", len(got.Segments))
+	}
+}
+
+func TestJournalStore_TruncatedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), timer.JOURNAL_FILE_NAME)
+	store := timer.NewJournalStore(path)
+
+	want := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T18:00:00Z", "2025-03-11T18:05:00Z"),
+		},
+	}
+	err := store.Dump("intact", want)
+	if err != nil {
+		t.Fatalf("Dump returned an error: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY | os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Couldn't open journal: %v", err)
+	}
+	_, err = file.WriteString(`{"name":"intact","timer":{"segm`)
+	_ = file.Close()
+	if err != nil {
+		t.Fatalf("Couldn't write truncated record: %v", err)
+	}
+
+	got, err := store.Load("intact", true)
+	if err != nil {
+		t.Fatalf("Load returned an error on a journal with a truncated record: %v", err)
+	}
+	if len(got.Segments) != 1 {
+		t.Errorf("Load returned the wrong timer: wanted %v, got %v", want, got)
+	}
+
+	err = store.Dump("after", want)
+	if err != nil {
+		t.Fatalf("Dump returned an error after a truncated record: %v", err)
+	}
+
+	_, err = store.Load("after", true)
+	if err != nil {
+		t.Errorf("Record appended after a truncated record was lost: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// FileLock is an advisory lock on a single timer. It is held across a whole load-modify-dump cycle so that
+// concurrent commands working on the same timer can't lose each other's updates.
+type FileLock struct {
+	file	*os.File
+}
+
+func Lock(name string, cacheDir string) (*FileLock, error) {
+	return lockPath(lockFilePath(name, cacheDir))
+}
+
+func lockFilePath(name string, cacheDir string) string {
+	return filepath.Join(cacheDir, EncodeName(name) + ".lock")
+}
+
+func lockPath(path string) (*FileLock, error) {
+	slog.Debug("Locking timer", "path", path)
+
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR | os.O_CREATE, 0644)
+		if err != nil {
+			msg := "Error opening lock file"
+			slog.Error(msg, "error", err)
+			return nil, fmt.Errorf(msg + ": %v", err)
+		}
+
+		err = lockFile(file)
+		if err != nil {
+			_ = file.Close()
+			msg := "Error locking timer"
+			slog.Error(msg, "error", err)
+			return nil, fmt.Errorf(msg + ": %v", err)
+		}
+
+		held, err := isLockFile(file, path)
+		if err != nil {
+			_ = file.Close()
+			msg := "Error checking lock file"
+			slog.Error(msg, "error", err)
+			return nil, fmt.Errorf(msg + ": %v", err)
+		}
+		if held {
+			return &FileLock{file: file}, nil
+		}
+
+		// The lock file was removed while we waited for it, so the lock guards nothing. Try the new one.
+		slog.Debug("Lock file was removed while waiting for it", "path", path)
+		_ = file.Close()
+	}
+}
+
+// isLockFile reports whether the locked file is still the one at path.
+func isLockFile(file *os.File, path string) (bool, error) {
+	current, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(info, current), nil
+}
+
+// removeLockFile removes the lock file of a timer that is being cleared. The caller must hold the lock;
+// anyone waiting for it notices the file is gone once they get it, and locks a new one.
+func removeLockFile(path string) {
+	slog.Debug("Removing lock file", "path", path)
+
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Couldn't remove lock file", "path", path, "error", err)
+	}
+}
+
+func (l *FileLock) Unlock() error {
+	slog.Debug("Unlocking timer", "path", l.file.Name())
+
+	err := unlockFile(l.file)
+	closeErr := l.file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		msg := "Error unlocking timer"
+		slog.Error(msg, "error", err)
+		return fmt.Errorf(msg + ": %v", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes to a temporary file next to path and renames it into place so that readers never
+// see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	dir, base := filepath.Split(path)
+	tmp, err := os.CreateTemp(dir, base + ".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package timer
+
+import (
+	"log/slog"
+	"os"
+)
+
+func lockFile(file *os.File) error {
+	slog.Debug("Advisory file locks are not supported on this platform", "path", file.Name())
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,138 @@
+package timer_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestLock_ParallelWriters(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	writers := 20
+	base := moment("2025-03-11T15:00:00Z")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			lock, err := timer.Lock("shared", cacheDir)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer func() { _ = lock.Unlock() }()
+
+			ticks, err := timer.Load("shared", cacheDir)
+			if err != nil {
+				errs <- err
+				return
+			}
+			// Each writer records the minute after the last one so that the intervals never overlap.
+			start := base.Add(time.Duration(len(ticks.Segments)) * time.Minute)
+			_ = ticks.Start(timer.FixedNowProvider{Moment: start})
+			_ = ticks.Stop(timer.FixedNowProvider{Moment: start.Add(time.Minute)})
+			errs <- ticks.Dump("shared", cacheDir)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Writer returned an error: %v", err)
+		}
+	}
+
+	ticks, err := timer.Load("shared", cacheDir, true)
+	if err != nil {
+		t.Fatalf("Couldn't load shared timer: %v", err)
+	}
+	if len(ticks.Segments) != writers {
+		t.Errorf("Parallel writers lost intervals: wanted %v segments, got %v", writers, len(ticks.Segments))
+	}
+}
+
+func TestLock_Unlock(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	lock, err := timer.Lock("relock", cacheDir)
+	if err != nil {
+		t.Fatalf("Lock returned an error: %v", err)
+	}
+	err = lock.Unlock()
+	if err != nil {
+		t.Fatalf("Unlock returned an error: %v", err)
+	}
+
+	lock, err = timer.Lock("relock", cacheDir)
+	if err != nil {
+		t.Fatalf("Lock returned an error after unlocking: %v", err)
+	}
+	err = lock.Unlock()
+	if err != nil {
+		t.Fatalf("Unlock returned an error: %v", err)
+	}
+}
+
+func TestLock_Clear(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	clear := func(lock *timer.FileLock) {
+		err := new(timer.Timer).Dump("cleared", cacheDir)
+		if err != nil {
+			t.Fatalf("Dump returned an error: %v", err)
+		}
+		err = timer.Clear("cleared", cacheDir)
+		if err != nil {
+			t.Fatalf("Clear returned an error: %v", err)
+		}
+		err = lock.Unlock()
+		if err != nil {
+			t.Fatalf("Unlock returned an error: %v", err)
+		}
+	}
+
+	lock, err := timer.Lock("cleared", cacheDir)
+	if err != nil {
+		t.Fatalf("Lock returned an error: %v", err)
+	}
+	clear(lock)
+	_, err = os.Stat(filepath.Join(cacheDir, "cleared.lock"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Clear didn't remove the lock file: %v", err)
+	}
+
+	lock, err = timer.Lock("cleared", cacheDir)
+	if err != nil {
+		t.Fatalf("Lock returned an error: %v", err)
+	}
+	locked := make(chan error)
+	go func() {
+		waiter, err := timer.Lock("cleared", cacheDir)
+		if err == nil {
+			err = waiter.Unlock()
+		}
+		locked <- err
+	}()
+	// Give the waiter time to block on the lock file that is about to be removed.
+	time.Sleep(50 * time.Millisecond)
+	clear(lock)
+
+	select {
+	case err = <-locked:
+		if err != nil {
+			t.Errorf("Waiting for a removed lock file returned an error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Waiting for a removed lock file never took the lock")
+	}
+}
@@ -0,0 +1,161 @@
+package timer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps timers in memory. It is meant for tests and for embedding gowatch where nothing should
+// touch the disk. Timers are kept serialized so that callers never share state with the store.
+type MemoryStore struct {
+	mutex		sync.Mutex
+	storeLock	sync.Mutex
+	state		State
+	data		map[string][]byte
+	locks		map[string]*sync.Mutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: map[string][]byte{},
+		locks: map[string]*sync.Mutex{},
+	}
+}
+
+func (s *MemoryStore) Exists(name string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.data[name]
+	return ok, nil
+}
+
+func (s *MemoryStore) Load(name string, mustExist ...bool) (*Timer, error) {
+	s.mutex.Lock()
+	data, ok := s.data[name]
+	s.mutex.Unlock()
+
+	t := new(Timer)
+	if !ok {
+		if len(mustExist) > 0 && mustExist[0] {
+			msg := "Timer does not exist"
+			slog.Error(msg, "name", name)
+			return nil, fmt.Errorf(msg + ": %v", name)
+		}
+		return t, nil
+	}
+
+	err := json.Unmarshal(data, t)
+	if err != nil {
+		msg := "Error loading timer data"
+		slog.Error(msg, "error", err)
+		return nil, fmt.Errorf(msg + ": %v", err)
+	}
+	return t, nil
+}
+
+func (s *MemoryStore) LoadAll() ([]*NamedTimer, error) {
+	s.mutex.Lock()
+	names := make([]string, 0, len(s.data))
+	for name := range s.data {
+		names = append(names, name)
+	}
+	s.mutex.Unlock()
+	sort.Strings(names)
+
+	namedTimers := make([]*NamedTimer, 0, len(names))
+	for _, name := range names {
+		ticks, err := s.Load(name, true)
+		if err != nil {
+			slog.Warn("Skipping timer that failed to load", "name", name, "error", err)
+			continue
+		}
+		namedTimers = append(namedTimers, &NamedTimer{Name: name, Ticks: ticks})
+	}
+	return namedTimers, nil
+}
+
+func (s *MemoryStore) Dump(name string, t *Timer) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		msg := "Error dumping timer data"
+		slog.Error(msg, "error", err)
+		return fmt.Errorf(msg + ": %v", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data[name] = data
+	return nil
+}
+
+func (s *MemoryStore) Clear(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.data[name]
+	if !ok {
+		msg := "Error clearing timer data"
+		slog.Error(msg, "name", name)
+		return fmt.Errorf(msg + ": timer does not exist: %v", name)
+	}
+	delete(s.data, name)
+	return nil
+}
+
+func (s *MemoryStore) ClearAll() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data = map[string][]byte{}
+	return nil
+}
+
+type memoryLock struct {
+	mutex	*sync.Mutex
+}
+
+func (l memoryLock) Unlock() error {
+	l.mutex.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Lock(name string) (Unlocker, error) {
+	s.mutex.Lock()
+	lock, ok := s.locks[name]
+	if !ok {
+		lock = new(sync.Mutex)
+		s.locks[name] = lock
+	}
+	s.mutex.Unlock()
+
+	lock.Lock()
+	return memoryLock{mutex: lock}, nil
+}
+
+func (s *MemoryStore) LockStore() (Unlocker, error) {
+	s.storeLock.Lock()
+	return memoryLock{mutex: &s.storeLock}, nil
+}
+
+func (s *MemoryStore) LoadState() (*State, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// Copy the events too, so that callers never share them with the store.
+	state := new(State)
+	if s.state.Started != nil {
+		state.RecordStart(s.state.Started.Name, s.state.Started.At)
+	}
+	if s.state.Stopped != nil {
+		state.RecordStop(s.state.Stopped.Name, s.state.Stopped.At)
+	}
+	return state, nil
+}
+
+func (s *MemoryStore) UpdateState(update func(state *State)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	update(&s.state)
+	return nil
+}
@@ -0,0 +1,50 @@
+package timer
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layouts accepted by ParseMoment, tried in order. Layouts without a date are taken to be on the day of now.
+var momentLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"15:04:05",
+	"15:04",
+}
+
+// ParseMoment parses a point in time given on the command line, like "14:05", "2026-10-18" or
+// "2026-10-18T14:05". Times without a zone are taken to be in the zone of now, and times without a date are
+// taken to be on the same day as now. The words "now", "today" and "yesterday" are also understood.
+func ParseMoment(text string, now time.Time) (time.Time, error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch text {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+
+	for _, layout := range momentLayouts {
+		moment, err := time.ParseInLocation(layout, text, now.Location())
+		if err != nil {
+			continue
+		}
+		if layout[0] == '1' {
+			moment = time.Date(
+				now.Year(), now.Month(), now.Day(),
+				moment.Hour(), moment.Minute(), moment.Second(), 0,
+				now.Location(),
+			)
+		}
+		return moment, nil
+	}
+
+	return time.Time{}, fmt.Errorf("Couldn't parse time %q: expected a time like 14:05, 2006-01-02 or 2006-01-02T14:05", text)
+}
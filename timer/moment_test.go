@@ -0,0 +1,41 @@
+package timer_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestParseMoment(t *testing.T) {
+	zone := time.FixedZone("test", -5 * 60 * 60)
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, zone)
+
+	cases := map[string]time.Time{
+		"now": now,
+		"today": time.Date(2026, 10, 18, 0, 0, 0, 0, zone),
+		"yesterday": time.Date(2026, 10, 17, 0, 0, 0, 0, zone),
+		"14:05": time.Date(2026, 10, 18, 14, 5, 0, 0, zone),
+		"14:05:30": time.Date(2026, 10, 18, 14, 5, 30, 0, zone),
+		"2026-10-01": time.Date(2026, 10, 1, 0, 0, 0, 0, zone),
+		"2026-10-18T14:05": time.Date(2026, 10, 18, 14, 5, 0, 0, zone),
+		"2026-10-18 14:05": time.Date(2026, 10, 18, 14, 5, 0, 0, zone),
+		"2026-10-18T14:05:00Z": time.Date(2026, 10, 18, 14, 5, 0, 0, time.UTC),
+	}
+
+	for text, want := range cases {
+		got, err := timer.ParseMoment(text, now)
+		if err != nil {
+			t.Errorf("ParseMoment returned an error for %q: %v", text, err)
+		} else if !got.Equal(want) {
+			t.Errorf("ParseMoment parsed %q wrong: wanted %v, got %v", text, want, got)
+		}
+	}
+
+	for _, text := range []string{"", "soon", "25:00", "2026-13-01"} {
+		_, err := timer.ParseMoment(text, now)
+		if err == nil {
+			t.Errorf("ParseMoment didn't return an error for %q", text)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package timer
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// NAME_SEPARATOR splits hierarchical timer names like "acme/backend/bugfix" into their parts.
+const NAME_SEPARATOR = "/"
+
+func CheckName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Timer name must not be empty")
+	}
+	for _, part := range strings.Split(name, NAME_SEPARATOR) {
+		if part == "" {
+			return fmt.Errorf("Invalid timer name %q: name parts must not be empty", name)
+		}
+	}
+	return nil
+}
+
+// EncodeName turns a timer name into a file name that is safe to use as a single path component. Path
+// separators and the escape character itself are percent-encoded, so flat names are stored unchanged.
+func EncodeName(name string) string {
+	builder := new(strings.Builder)
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		if b == '%' || b == '/' || b == '\\' || b < ' ' || b == 0x7f {
+			fmt.Fprintf(builder, "%%%02X", b)
+		} else {
+			builder.WriteByte(b)
+		}
+	}
+	return builder.String()
+}
+
+func DecodeName(encoded string) string {
+	name, err := url.PathUnescape(encoded)
+	if err != nil {
+		slog.Debug("Using undecodable file name as timer name", "name", encoded, "error", err)
+		return encoded
+	}
+	return name
+}
+
+// nameFromFile returns the name of the timer kept in a file with the given base name. Files written before
+// names were encoded hold the raw name, so a base name that doesn't round trip is taken as it is.
+func nameFromFile(base string) string {
+	name := DecodeName(base)
+	if EncodeName(name) != base {
+		return base
+	}
+	return name
+}
+
+// IsWithin reports whether name is root itself or one of its descendants.
+func IsWithin(name string, root string) bool {
+	return name == root || strings.HasPrefix(name, root + NAME_SEPARATOR)
+}
+
+func SelectSubtree(nts []*NamedTimer, root string) []*NamedTimer {
+	matches := make([]*NamedTimer, 0)
+	for _, nt := range nts {
+		if IsWithin(nt.Name, root) {
+			matches = append(matches, nt)
+		}
+	}
+	return matches
+}
+
+func IsHierarchical(nts []*NamedTimer) bool {
+	for _, nt := range nts {
+		if strings.Contains(nt.Name, NAME_SEPARATOR) {
+			return true
+		}
+	}
+	return false
+}
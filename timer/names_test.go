@@ -0,0 +1,150 @@
+package timer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestEncodeName(t *testing.T) {
+	cases := map[string]string{
+		"default": "default",
+		"acme/backend/bugfix": "acme%2Fbackend%2Fbugfix",
+		"100%": "100%25",
+		`back\slash`: "back%5Cslash",
+	}
+
+	for name, want := range cases {
+		got := timer.EncodeName(name)
+		if got != want {
+			t.Errorf("EncodeName(%q) returned the wrong file name: wanted %q, got %q", name, want, got)
+		}
+		if timer.DecodeName(got) != name {
+			t.Errorf("DecodeName didn't round trip %q: got %q", name, timer.DecodeName(got))
+		}
+	}
+
+	if timer.DecodeName("50%off") != "50%off" {
+		t.Errorf("DecodeName didn't fall back to the raw name for an undecodable file name")
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	for _, name := range []string{"default", "acme/backend", "a b/c"} {
+		err := timer.CheckName(name)
+		if err != nil {
+			t.Errorf("CheckName rejected valid name %q: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "/acme", "acme/", "acme//backend"} {
+		err := timer.CheckName(name)
+		if err == nil {
+			t.Errorf("CheckName didn't reject invalid name %q", name)
+		}
+	}
+}
+
+func TestFileStore_HierarchicalNames(t *testing.T) {
+	cacheDir := t.TempDir()
+	store := timer.NewFileStore(cacheDir)
+
+	ticks := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T19:00:00Z", "2025-03-11T19:05:00Z"),
+		},
+	}
+	err := store.Dump("acme/backend", ticks)
+	if err != nil {
+		t.Fatalf("Dump returned an error for a hierarchical name: %v", err)
+	}
+
+	_, err = os.Stat(filepath.Join(cacheDir, "acme%2Fbackend.json"))
+	if err != nil {
+		t.Errorf("Dump didn't store the timer under an encoded file name: %v", err)
+	}
+
+	all, err := store.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll returned an error: %v", err)
+	}
+	if len(all) != 1 || all[0].Name != "acme/backend" {
+		t.Errorf("LoadAll didn't decode the hierarchical name: got %v", all)
+	}
+}
+
+func TestFileStore_LegacyNames(t *testing.T) {
+	cacheDir := t.TempDir()
+	store := timer.NewFileStore(cacheDir)
+
+	// Timers written before names were encoded keep their raw names as file names.
+	data := []byte(`{"segments":[{"start":"2025-03-11T19:00:00Z","end":"2025-03-11T19:05:00Z","duration":300000000000}]}`)
+	for _, file := range []string{"50%.json", "a%20b.json"} {
+		err := os.WriteFile(filepath.Join(cacheDir, file), data, 0644)
+		if err != nil {
+			t.Fatalf("Couldn't write old timer file: %v", err)
+		}
+	}
+
+	all, err := store.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll returned an error: %v", err)
+	}
+	if len(all) != 2 || all[0].Name != "50%" || all[1].Name != "a%20b" {
+		t.Errorf("LoadAll didn't keep the raw names of old timer files: got %v", all)
+	}
+
+	ticks, err := store.Load("50%", true)
+	if err != nil {
+		t.Fatalf("Load didn't find an old timer file: %v", err)
+	}
+	if ticks.Elapsed() != span("5m") {
+		t.Errorf("Load read the wrong timer: got %v", ticks)
+	}
+	if exists, _ := store.Exists("a%20b"); !exists {
+		t.Errorf("Exists didn't find an old timer file")
+	}
+	if exists, _ := store.Exists("a b"); exists {
+		t.Errorf("Exists mistook an old timer file for a different name")
+	}
+
+	_ = ticks.Start(freeze(t, "2025-03-11T20:00:00Z"))
+	err = store.Dump("50%", ticks)
+	if err != nil {
+		t.Fatalf("Dump returned an error: %v", err)
+	}
+	all, _ = store.LoadAll()
+	if len(all) != 2 || !all[0].Ticks.IsRunning() {
+		t.Errorf("Dump didn't update the old timer file in place: got %v", all)
+	}
+
+	err = store.Clear("a%20b")
+	if err != nil {
+		t.Fatalf("Clear returned an error for an old timer file: %v", err)
+	}
+	all, _ = store.LoadAll()
+	if len(all) != 1 {
+		t.Errorf("Clear didn't remove the old timer file: got %v", all)
+	}
+}
+
+func TestSelectSubtree(t *testing.T) {
+	nts := []*timer.NamedTimer{
+		{Name: "acme"},
+		{Name: "acme/backend"},
+		{Name: "acme/backend/bugfix"},
+		{Name: "acmecorp"},
+		{Name: "other"},
+	}
+
+	got := timer.SelectSubtree(nts, "acme")
+	if len(got) != 3 {
+		t.Errorf("SelectSubtree returned the wrong timers: got %v", got)
+	}
+	for _, nt := range got {
+		if nt.Name == "acmecorp" {
+			t.Errorf("SelectSubtree matched a sibling with a shared prefix")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package timer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// STATE_FILE_NAME holds the State next to the timers. It doesn't end in .json, so it is never taken for a
+// timer.
+const STATE_FILE_NAME = "gowatch.state"
+
+// STATE_LOCK_FILE_NAME serializes updates to the state file. Like STORE_LOCK_FILE_NAME, it can't clash with
+// a timer's lock file.
+const STATE_LOCK_FILE_NAME = "%state.lock"
+
+// Event records a timer being started or stopped.
+type Event struct {
+	Name	string		`json:"name"`
+	At		time.Time	`json:"at"`
+}
+
+// State is what the store remembers across timers: which timer was started last and which was stopped last.
+type State struct {
+	Started	*Event	`json:"started,omitempty"`
+	Stopped	*Event	`json:"stopped,omitempty"`
+}
+
+// RecordStart remembers a start, unless a later one is already recorded. Starts can be backdated with --at,
+// so the last one recorded isn't always the most recent.
+func (s *State) RecordStart(name string, at time.Time) {
+	if s.Started == nil || !at.Before(s.Started.At) {
+		s.Started = &Event{Name: name, At: at}
+	}
+}
+
+// RecordStop remembers a stop, unless a later one is already recorded.
+func (s *State) RecordStop(name string, at time.Time) {
+	if s.Stopped == nil || !at.Before(s.Stopped.At) {
+		s.Stopped = &Event{Name: name, At: at}
+	}
+}
+
+// Rename points the events recorded for the timer from at the timer to.
+func (s *State) Rename(from string, to string) {
+	for _, event := range []*Event{s.Started, s.Stopped} {
+		if event != nil && event.Name == from {
+			event.Name = to
+		}
+	}
+}
+
+// Forget drops the events recorded for a timer that was removed or replaced.
+func (s *State) Forget(name string) {
+	if s.Started != nil && s.Started.Name == name {
+		s.Started = nil
+	}
+	if s.Stopped != nil && s.Stopped.Name == name {
+		s.Stopped = nil
+	}
+}
+
+// Last returns the more recent of the last start and the last stop, and whether it was a start. A start wins
+// over a stop at the same moment, which is how an exclusive start pauses the other timers. It returns nil if
+// no timer was started or stopped yet.
+func (s *State) Last() (*Event, bool) {
+	if s.Started == nil {
+		return s.Stopped, false
+	}
+	if s.Stopped == nil || !s.Started.At.Before(s.Stopped.At) {
+		return s.Started, true
+	}
+	return s.Stopped, false
+}
+
+func loadStateFile(dir string) (*State, error) {
+	state := new(State)
+	data, err := os.ReadFile(filepath.Join(dir, STATE_FILE_NAME))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		msg := "Error reading state file"
+		slog.Error(msg, "error", err)
+		return nil, fmt.Errorf(msg + ": %v", err)
+	}
+
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		msg := "Error loading state"
+		slog.Error(msg, "error", err)
+		return nil, fmt.Errorf(msg + ": %v", err)
+	}
+	return state, nil
+}
+
+// updateStateFile changes the state file under its own lock. No other lock is taken while it is held, so it
+// can be called whatever locks the caller holds.
+func updateStateFile(dir string, update func(state *State)) error {
+	lock, err := lockPath(filepath.Join(dir, STATE_LOCK_FILE_NAME))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	state, err := loadStateFile(dir)
+	if err != nil {
+		return err
+	}
+	update(state)
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		msg := "Error dumping state"
+		slog.Error(msg, "error", err)
+		return fmt.Errorf(msg + ": %v", err)
+	}
+
+	slog.Debug("Writing state file", "dir", dir)
+	err = writeFileAtomic(filepath.Join(dir, STATE_FILE_NAME), data)
+	if err != nil {
+		msg := "Error writing state file"
+		slog.Error(msg, "error", err)
+		return fmt.Errorf(msg + ": %v", err)
+	}
+	return nil
+}
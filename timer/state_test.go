@@ -0,0 +1,90 @@
+package timer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestStore_State(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			state, err := store.LoadState()
+			if err != nil {
+				t.Fatalf("LoadState returned an error: %v", err)
+			}
+			if last, _ := state.Last(); last != nil {
+				t.Errorf("LoadState didn't start out empty: got %v", last)
+			}
+
+			err = store.UpdateState(func(state *timer.State) {
+				state.RecordStart("review", moment("2025-03-11T09:00:00Z"))
+				state.RecordStop("standup", moment("2025-03-11T08:45:00Z"))
+			})
+			if err != nil {
+				t.Fatalf("UpdateState returned an error: %v", err)
+			}
+
+			state, err = store.LoadState()
+			if err != nil {
+				t.Fatalf("LoadState returned an error: %v", err)
+			}
+			last, started := state.Last()
+			if last == nil || last.Name != "review" || !started {
+				t.Errorf("Last didn't return the latest start: got %v (started: %v)", last, started)
+			}
+			if state.Stopped == nil || state.Stopped.Name != "standup" {
+				t.Errorf("LoadState didn't keep the last stop: got %v", state.Stopped)
+			}
+
+			_ = store.UpdateState(func(state *timer.State) {
+				state.RecordStop("review", moment("2025-03-11T10:00:00Z"))
+			})
+			state, _ = store.LoadState()
+			last, started = state.Last()
+			if last == nil || last.Name != "review" || started {
+				t.Errorf("Last didn't return the latest stop: got %v (started: %v)", last, started)
+			}
+
+			all, err := store.LoadAll()
+			if err != nil || len(all) != 0 {
+				t.Errorf("The state was listed as a timer: got %v (%v)", all, err)
+			}
+		})
+	}
+}
+
+func TestState_RecordOlder(t *testing.T) {
+	state := new(timer.State)
+	state.RecordStart("review", moment("2025-03-11T09:00:00Z"))
+	state.RecordStart("standup", moment("2025-03-11T08:00:00Z"))
+	state.RecordStop("review", moment("2025-03-11T10:00:00Z"))
+	state.RecordStop("standup", moment("2025-03-11T08:30:00Z"))
+
+	if state.Started.Name != "review" {
+		t.Errorf("RecordStart replaced a later start: got %v", state.Started)
+	}
+	if state.Stopped.Name != "review" {
+		t.Errorf("RecordStop replaced a later stop: got %v", state.Stopped)
+	}
+
+	state.RecordStart("standup", moment("2025-03-11T09:00:00Z"))
+	if state.Started.Name != "standup" {
+		t.Errorf("RecordStart didn't replace a start at the same moment: got %v", state.Started)
+	}
+}
+
+func TestLoadState_Invalid(t *testing.T) {
+	cacheDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(cacheDir, timer.STATE_FILE_NAME), []byte("{"), 0644)
+	if err != nil {
+		t.Fatalf("Couldn't write state file: %v", err)
+	}
+
+	_, err = timer.NewFileStore(cacheDir).LoadState()
+	if err == nil {
+		t.Errorf("LoadState didn't error on an invalid state file")
+	}
+}
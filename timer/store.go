@@ -0,0 +1,159 @@
+package timer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store persists named timers. Every command goes through a Store so that the storage backend can be
+// swapped without touching the code that manipulates timers.
+type Store interface {
+	Exists(name string) (bool, error)
+	Load(name string, mustExist ...bool) (*Timer, error)
+	LoadAll() ([]*NamedTimer, error)
+	Dump(name string, t *Timer) error
+	// Clear removes a timer along with its lock file. The caller must hold the timer's lock.
+	Clear(name string) error
+	// ClearAll removes every timer and their lock files. The caller must hold every timer's lock.
+	ClearAll() error
+	Lock(name string) (Unlocker, error)
+	// LockStore locks the store as a whole. Updates that touch several timers hold it so that readers taking
+	// it too see either all of the update or none of it. It must be taken before any timer's lock.
+	LockStore() (Unlocker, error)
+	LoadState() (*State, error)
+	// UpdateState changes the state atomically. It takes a lock of its own, which can be taken while holding
+	// any other lock.
+	UpdateState(update func(state *State)) error
+}
+
+type Unlocker interface {
+	Unlock() error
+}
+
+type StoreOpener func(location string) (Store, error)
+
+var (
+	storeMutex		sync.Mutex
+	storeOpeners	= map[string]StoreOpener{}
+)
+
+func init() {
+	RegisterStore("file", func(location string) (Store, error) {
+		return NewFileStore(location), nil
+	})
+	RegisterStore("memory", func(_ string) (Store, error) {
+		return NewMemoryStore(), nil
+	})
+	RegisterStore("journal", func(location string) (Store, error) {
+		return NewJournalStore(filepath.Join(location, JOURNAL_FILE_NAME)), nil
+	})
+}
+
+// RegisterStore makes a storage backend available to OpenStore under the given kind.
+func RegisterStore(kind string, opener StoreOpener) {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	storeOpeners[kind] = opener
+}
+
+func StoreKinds() []string {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+
+	kinds := make([]string, 0, len(storeOpeners))
+	for kind := range storeOpeners {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func OpenStore(kind string, location string) (Store, error) {
+	storeMutex.Lock()
+	opener, ok := storeOpeners[kind]
+	storeMutex.Unlock()
+
+	if !ok {
+		msg := "Unknown store kind"
+		slog.Error(msg, "kind", kind, "known", StoreKinds())
+		return nil, fmt.Errorf(msg + ": %v", kind)
+	}
+
+	slog.Debug("Opening store", "kind", kind, "location", location)
+	return opener(location)
+}
+
+// STORE_LOCK_FILE_NAME is the FileStore's store-wide lock. EncodeName escapes every %, so no timer's lock file
+// can have this name.
+const STORE_LOCK_FILE_NAME = "%store.lock"
+
+// Snapshot loads every timer under the store lock, so that it never sees an update to several timers, like an
+// exclusive start, half done.
+func Snapshot(s Store) ([]*NamedTimer, error) {
+	lock, err := s.LockStore()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	return s.LoadAll()
+}
+
+// FileStore keeps one JSON file per timer in a cache directory.
+type FileStore struct {
+	CacheDir	string
+}
+
+func NewFileStore(cacheDir string) *FileStore {
+	return &FileStore{CacheDir: cacheDir}
+}
+
+func (s *FileStore) Exists(name string) (bool, error) {
+	_, err := os.Stat(timerPath(name, s.CacheDir))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("Error checking for timer file: %v", err)
+	}
+	return true, nil
+}
+
+func (s *FileStore) Load(name string, mustExist ...bool) (*Timer, error) {
+	return Load(name, s.CacheDir, mustExist...)
+}
+
+func (s *FileStore) LoadAll() ([]*NamedTimer, error) {
+	return LoadAll(s.CacheDir)
+}
+
+func (s *FileStore) Dump(name string, t *Timer) error {
+	return t.Dump(name, s.CacheDir)
+}
+
+func (s *FileStore) Clear(name string) error {
+	return Clear(name, s.CacheDir)
+}
+
+func (s *FileStore) ClearAll() error {
+	return ClearAll(s.CacheDir)
+}
+
+func (s *FileStore) Lock(name string) (Unlocker, error) {
+	return Lock(name, s.CacheDir)
+}
+
+func (s *FileStore) LockStore() (Unlocker, error) {
+	return lockPath(filepath.Join(s.CacheDir, STORE_LOCK_FILE_NAME))
+}
+
+func (s *FileStore) LoadState() (*State, error) {
+	return loadStateFile(s.CacheDir)
+}
+
+func (s *FileStore) UpdateState(update func(state *State)) error {
+	return updateStateFile(s.CacheDir, update)
+}
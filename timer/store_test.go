@@ -0,0 +1,274 @@
+package timer_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func stores(t *testing.T) map[string]timer.Store {
+	return map[string]timer.Store{
+		"file": timer.NewFileStore(t.TempDir()),
+		"memory": timer.NewMemoryStore(),
+		"journal": timer.NewJournalStore(filepath.Join(t.TempDir(), timer.JOURNAL_FILE_NAME)),
+	}
+}
+
+func TestStore_RoundTrip(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			_, err := store.Load("missing", true)
+			if err == nil {
+				t.Errorf("Load didn't error on a missing timer when mustExist was set")
+			}
+
+			got, err := store.Load("missing")
+			if err != nil {
+				t.Errorf("Load returned an error on a missing timer: %v", err)
+			} else if !reflect.DeepEqual(got, new(timer.Timer)) {
+				t.Errorf("Load returned a non-empty timer for a missing timer: %v", got)
+			}
+
+			one := &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-03-11T16:00:00Z", "2025-03-11T16:05:00Z"),
+				},
+			}
+			two := &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-03-11T16:10:00Z", ""),
+				},
+			}
+			for name, ticks := range map[string]*timer.Timer{"one": one, "two": two} {
+				err = store.Dump(name, ticks)
+				if err != nil {
+					t.Fatalf("Dump returned an error: %v", err)
+				}
+			}
+
+			ok, err := store.Exists("one")
+			if err != nil {
+				t.Errorf("Exists returned an error: %v", err)
+			} else if !ok {
+				t.Errorf("Exists returned false for a dumped timer")
+			}
+
+			got, err = store.Load("one", true)
+			if err != nil {
+				t.Fatalf("Load returned an error: %v", err)
+			} else if !reflect.DeepEqual(got, one) {
+				t.Errorf("Load returned the wrong timer: wanted %v, got %v", one, got)
+			}
+
+			want := []*timer.NamedTimer{
+				{Name: "one", Ticks: one},
+				{Name: "two", Ticks: two},
+			}
+			all, err := store.LoadAll()
+			if err != nil {
+				t.Fatalf("LoadAll returned an error: %v", err)
+			} else if !reflect.DeepEqual(all, want) {
+				t.Errorf("LoadAll returned the wrong timers: wanted %v, got %v", want, all)
+			}
+
+			err = store.Clear("one")
+			if err != nil {
+				t.Errorf("Clear returned an error: %v", err)
+			}
+			err = store.Clear("one")
+			if err == nil {
+				t.Errorf("Clear didn't return an error on a missing timer")
+			}
+
+			err = store.ClearAll()
+			if err != nil {
+				t.Errorf("ClearAll returned an error: %v", err)
+			}
+			all, err = store.LoadAll()
+			if err != nil {
+				t.Fatalf("LoadAll returned an error: %v", err)
+			} else if len(all) != 0 {
+				t.Errorf("ClearAll left timers behind: %v", all)
+			}
+		})
+	}
+}
+
+func TestStore_ParallelWriters(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			writers := 20
+			base := moment("2025-03-11T16:00:00Z")
+
+			var wg sync.WaitGroup
+			for range writers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					lock, err := store.Lock("shared")
+					if err != nil {
+						t.Errorf("Lock returned an error: %v", err)
+						return
+					}
+					defer func() { _ = lock.Unlock() }()
+
+					ticks, err := store.Load("shared")
+					if err != nil {
+						t.Errorf("Load returned an error: %v", err)
+						return
+					}
+					// Each writer records the minute after the last one so that the intervals never overlap.
+					start := base.Add(time.Duration(len(ticks.Segments)) * time.Minute)
+					_ = ticks.Start(timer.FixedNowProvider{Moment: start})
+					_ = ticks.Stop(timer.FixedNowProvider{Moment: start.Add(time.Minute)})
+					err = store.Dump("shared", ticks)
+					if err != nil {
+						t.Errorf("Dump returned an error: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			ticks, err := store.Load("shared", true)
+			if err != nil {
+				t.Fatalf("Couldn't load shared timer: %v", err)
+			}
+			if len(ticks.Segments) != writers {
+				t.Errorf("Parallel writers lost intervals: wanted %v segments, got %v", writers, len(ticks.Segments))
+			}
+		})
+	}
+}
+
+func TestStore_ParallelNames(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			writers := 20
+
+			var wg sync.WaitGroup
+			for i := range writers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					name := fmt.Sprintf("timer-%d", i)
+					lock, err := store.Lock(name)
+					if err != nil {
+						t.Errorf("Lock returned an error: %v", err)
+						return
+					}
+					defer func() { _ = lock.Unlock() }()
+
+					ticks := &timer.Timer{Segments: []timer.Segment{segment("2025-03-11T16:00:00Z", "2025-03-11T16:01:00Z")}}
+					err = store.Dump(name, ticks)
+					if err != nil {
+						t.Errorf("Dump returned an error: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			all, err := store.LoadAll()
+			if err != nil {
+				t.Fatalf("LoadAll returned an error: %v", err)
+			}
+			if len(all) != writers {
+				t.Errorf("Parallel writers to different timers lost timers: wanted %v, got %v", writers, len(all))
+			}
+		})
+	}
+}
+
+func TestStore_TwoLocks(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			done := make(chan error)
+			go func() {
+				one, err := store.Lock("one")
+				if err != nil {
+					done <- err
+					return
+				}
+				defer func() { _ = one.Unlock() }()
+
+				two, err := store.Lock("two")
+				if err != nil {
+					done <- err
+					return
+				}
+				done <- two.Unlock()
+			}()
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("Locking returned an error: %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Holding two timer locks at once deadlocked")
+			}
+		})
+	}
+}
+
+func TestStore_NestedLocks(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			done := make(chan error)
+			go func() {
+				unlockers := []timer.Unlocker{}
+				defer func() {
+					for _, lock := range unlockers {
+						_ = lock.Unlock()
+					}
+				}()
+
+				lock, err := store.LockStore()
+				if err != nil {
+					done <- err
+					return
+				}
+				unlockers = append(unlockers, lock)
+				for _, name := range []string{"one", "two"} {
+					lock, err = store.Lock(name)
+					if err != nil {
+						done <- err
+						return
+					}
+					unlockers = append(unlockers, lock)
+				}
+				done <- nil
+			}()
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("Locking returned an error: %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Holding the store lock and two timer locks at once deadlocked")
+			}
+		})
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	store, err := timer.OpenStore("file", t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenStore returned an error for the file store: %v", err)
+	}
+	if _, ok := store.(*timer.FileStore); !ok {
+		t.Errorf("OpenStore returned the wrong store type: %T", store)
+	}
+
+	_, err = timer.OpenStore("bogus", "")
+	if err == nil {
+		t.Errorf("OpenStore didn't return an error for an unknown kind")
+	}
+}
@@ -0,0 +1,70 @@
+package timer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func CheckTag(tag string) error {
+	if tag == "" || strings.ContainsAny(tag, ", \t\n") {
+		return fmt.Errorf("Invalid tag %q: tags must be non-empty and contain no commas or whitespace", tag)
+	}
+	return nil
+}
+
+func (t *Timer) HasTag(tag string) bool {
+	return slices.Contains(t.Tags, tag)
+}
+
+func (t *Timer) AddTags(tags ...string) error {
+	for _, tag := range tags {
+		err := CheckTag(tag)
+		if err != nil {
+			return err
+		}
+		if !t.HasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+	slices.Sort(t.Tags)
+	return nil
+}
+
+func (t *Timer) RemoveTags(tags ...string) {
+	t.Tags = slices.DeleteFunc(t.Tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+	if len(t.Tags) == 0 {
+		t.Tags = nil
+	}
+}
+
+// MatchTags reports whether the timer carries every tag in include and none of the tags in exclude.
+func (t *Timer) MatchTags(include []string, exclude []string) bool {
+	for _, tag := range include {
+		if !t.HasTag(tag) {
+			return false
+		}
+	}
+	for _, tag := range exclude {
+		if t.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func FilterTags(nts []*NamedTimer, include []string, exclude []string) []*NamedTimer {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nts
+	}
+
+	matches := make([]*NamedTimer, 0, len(nts))
+	for _, nt := range nts {
+		if nt.Ticks.MatchTags(include, exclude) {
+			matches = append(matches, nt)
+		}
+	}
+	return matches
+}
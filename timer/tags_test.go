@@ -0,0 +1,97 @@
+package timer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestAddTags(t *testing.T) {
+	ticks := new(timer.Timer)
+
+	err := ticks.AddTags("meeting", "client-a", "meeting")
+	if err != nil {
+		t.Fatalf("AddTags returned an error: %v", err)
+	}
+
+	want := []string{"client-a", "meeting"}
+	if !reflect.DeepEqual(want, ticks.Tags) {
+		t.Errorf("AddTags didn't keep a sorted set: wanted %v, got %v", want, ticks.Tags)
+	}
+
+	for _, bad := range []string{"", "has space", "a,b"} {
+		err = ticks.AddTags(bad)
+		if err == nil {
+			t.Errorf("AddTags didn't reject invalid tag %q", bad)
+		}
+	}
+}
+
+func TestRemoveTags(t *testing.T) {
+	ticks := &timer.Timer{Tags: []string{"client-a", "meeting"}}
+
+	ticks.RemoveTags("meeting", "missing")
+	want := []string{"client-a"}
+	if !reflect.DeepEqual(want, ticks.Tags) {
+		t.Errorf("RemoveTags didn't remove the tag: wanted %v, got %v", want, ticks.Tags)
+	}
+
+	ticks.RemoveTags("client-a")
+	if ticks.Tags != nil {
+		t.Errorf("RemoveTags didn't clear the tags: got %v", ticks.Tags)
+	}
+}
+
+func TestFilterTags(t *testing.T) {
+	nts := []*timer.NamedTimer{
+		{Name: "one", Ticks: &timer.Timer{Tags: []string{"client-a", "meeting"}}},
+		{Name: "two", Ticks: &timer.Timer{Tags: []string{"client-a"}}},
+		{Name: "three", Ticks: &timer.Timer{}},
+	}
+
+	names := func(nts []*timer.NamedTimer) []string {
+		names := make([]string, 0)
+		for _, nt := range nts {
+			names = append(names, nt.Name)
+		}
+		return names
+	}
+
+	cases := []struct {
+		include	[]string
+		exclude	[]string
+		want	[]string
+	}{
+		{nil, nil, []string{"one", "two", "three"}},
+		{[]string{"client-a"}, nil, []string{"one", "two"}},
+		{[]string{"client-a", "meeting"}, nil, []string{"one"}},
+		{nil, []string{"meeting"}, []string{"two", "three"}},
+		{[]string{"client-a"}, []string{"meeting"}, []string{"two"}},
+	}
+
+	for _, c := range cases {
+		got := names(timer.FilterTags(nts, c.include, c.exclude))
+		if !reflect.DeepEqual(c.want, got) {
+			t.Errorf("FilterTags(%v, %v) returned the wrong timers: wanted %v, got %v", c.include, c.exclude, c.want, got)
+		}
+	}
+}
+
+func TestDump_Tags(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	want := &timer.Timer{Tags: []string{"client-a"}}
+	err := want.Dump("tagged", cacheDir)
+	if err != nil {
+		t.Fatalf("Dump returned an error: %v", err)
+	}
+
+	got, err := timer.Load("tagged", cacheDir, true)
+	if err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Tags didn't survive a dump and load: wanted %v, got %v", want, got)
+	}
+}
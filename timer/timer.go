@@ -2,6 +2,7 @@ package timer
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -13,10 +14,31 @@ import (
 const APP_NAME = "gowatch"
 const DEFAULT_TIMER_NAME = "default"
 
+// Precision is how finely durations are rounded for display. The command line sets it from the config.
+var Precision = time.Millisecond
+
+type Segment struct {
+	Start		time.Time		`json:"start"`
+	End			time.Time		`json:"end"`
+	Duration	time.Duration	`json:"duration"`
+	Note		string			`json:"note,omitempty"`
+	Kind		string			`json:"kind,omitempty"`
+	Command		string			`json:"command,omitempty"`
+	ExitCode	*int			`json:"exit_code,omitempty"`
+}
+
+type Lap struct {
+	Name	string			`json:"name,omitempty"`
+	Time	time.Time		`json:"time"`
+	Elapsed	time.Duration	`json:"elapsed"`
+	Note	string			`json:"note,omitempty"`
+}
+
 type Timer struct {
-	TotalTime	time.Duration	`json:"total"`
-	StartTime	time.Time		`json:"start"`
-	EndTime		time.Time		`json:"end"`
+	Segments	[]Segment	`json:"segments"`
+	Laps		[]Lap		`json:"laps,omitempty"`
+	Target		time.Duration	`json:"target,omitempty"`
+	Tags		[]string		`json:"tags,omitempty"`
 }
 
 type NamedTimer struct {
@@ -80,15 +102,165 @@ func EnsureDir(dir string) error {
 	return nil
 }
 
+func (s *Segment) IsOpen() bool {
+	return !s.Start.IsZero() && s.End.IsZero()
+}
+
+func (s *Segment) Elapsed(nowProviderArg ...NowProvider) time.Duration {
+	if s.IsOpen() {
+		return now(nowProviderArg).Sub(s.Start)
+	}
+	return s.Duration
+}
+
+// Clip returns the part of the segment that falls in [since, until), and false if none of it does. A zero
+// since or until leaves that side of the range open. A running segment is taken to end at now, but stays open
+// unless until cuts it short. Adjustments cover no time, so they are kept whole if they were made in the range.
+func (s *Segment) Clip(since time.Time, until time.Time, now time.Time) (Segment, bool) {
+	if s.IsAdjustment() {
+		if (since.IsZero() || !s.Start.Before(since)) && (until.IsZero() || s.Start.Before(until)) {
+			return *s, true
+		}
+		return Segment{}, false
+	}
+
+	clipped := *s
+	end := s.End
+	if s.IsOpen() {
+		end = now
+	}
+	if !since.IsZero() && clipped.Start.Before(since) {
+		clipped.Start = since
+	}
+	if !until.IsZero() && end.After(until) {
+		end = until
+		clipped.End = until
+	}
+	if !end.After(clipped.Start) {
+		return Segment{}, false
+	}
+
+	if !clipped.IsOpen() {
+		clipped.End = end
+	}
+	clipped.Duration = end.Sub(clipped.Start)
+	return clipped, true
+}
+
+func (s *Segment) String() string {
+	text := fmt.Sprintf(
+		"(%s -- %s) -> %s",
+		s.Start.Format(time.RFC3339),
+		s.End.Format(time.RFC3339),
+		s.Elapsed().Round(Precision).String(),
+	)
+	if s.IsManual() {
+		text += " [" + s.Kind + "]"
+	}
+	if s.Command != "" {
+		text += " [" + s.Command
+		if s.ExitCode != nil {
+			text += fmt.Sprintf(" exited %d", *s.ExitCode)
+		}
+		text += "]"
+	}
+	if s.Note != "" {
+		text += ": " + s.Note
+	}
+	return text
+}
+
+// AddNote appends to the segment's note so that notes given when starting and stopping are both kept.
+func (s *Segment) AddNote(note string) {
+	if note == "" {
+		return
+	}
+	if s.Note != "" {
+		s.Note += "; "
+	}
+	s.Note += note
+}
+
+// RecordExit notes the command that ran during the segment and the code it exited with.
+func (s *Segment) RecordExit(command string, code int) {
+	s.Command = command
+	s.ExitCode = &code
+}
+
 func (t *Timer) String() string {
 	return fmt.Sprintf(
 		"(%s -- %s) -> %s",
-		t.StartTime.Format(time.RFC3339),
-		t.EndTime.Format(time.RFC3339),
+		t.Started().Format(time.RFC3339),
+		t.Ended().Format(time.RFC3339),
 		t.ElapsedString(),
 	)
 }
 
+func (t *Timer) Started() time.Time {
+	if len(t.Segments) == 0 {
+		return time.Time{}
+	}
+	return t.Segments[0].Start
+}
+
+func (t *Timer) Ended() time.Time {
+	if len(t.Segments) == 0 {
+		return time.Time{}
+	}
+	return t.Segments[len(t.Segments) - 1].End
+}
+
+// UnmarshalJSON reads the segment history and upgrades files written before segments existed.
+//
+// Old files only carried the total time and the bounds of the last interval. Those are converted into
+// a segment for the last interval preceded by a single segment holding whatever time came before it.
+func (t *Timer) UnmarshalJSON(data []byte) error {
+	type timerData Timer
+	aux := struct {
+		*timerData
+		TotalTime	time.Duration	`json:"total"`
+		StartTime	time.Time		`json:"start"`
+		EndTime		time.Time		`json:"end"`
+	}{
+		timerData: (*timerData)(t),
+	}
+
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+
+	if len(t.Segments) == 0 && !aux.StartTime.IsZero() {
+		slog.Debug("Upgrading legacy timer data")
+		t.Segments = legacySegments(aux.TotalTime, aux.StartTime, aux.EndTime)
+	}
+	return nil
+}
+
+func legacySegments(total time.Duration, start time.Time, end time.Time) []Segment {
+	segments := make([]Segment, 0, 2)
+
+	prior := total
+	last := Segment{Start: start}
+	if !end.IsZero() {
+		last.End = end
+		last.Duration = end.Sub(start)
+		prior -= last.Duration
+	}
+
+	if prior > 0 {
+		segments = append(
+			segments,
+			Segment{
+				Start: start.Add(-prior),
+				End: start,
+				Duration: prior,
+			},
+		)
+	}
+	return append(segments, last)
+}
+
 func (nt *NamedTimer) String() string {
 	return fmt.Sprintf(
 		"%s: %s\n",
@@ -98,18 +270,44 @@ func (nt *NamedTimer) String() string {
 }
 
 func (t *Timer) Elapsed(nowProviderArg ...NowProvider) time.Duration {
-	if !t.StartTime.IsZero() && t.EndTime.IsZero() {
-		endTime := now(nowProviderArg)
-		totalTime := endTime.Sub(t.StartTime)
-		return totalTime
+	var elapsed time.Duration
+	for i := range t.Segments {
+		elapsed += t.Segments[i].Elapsed(nowProviderArg...)
 	}
-
-	return t.TotalTime
+	return elapsed
 }
 
 func (t *Timer) ElapsedString(nowProviderArg ...NowProvider) string {
 	elapsed := t.Elapsed(nowProviderArg...)
-	return elapsed.Round(time.Millisecond).String()
+	return elapsed.Round(Precision).String()
+}
+
+func (t *Timer) IsCountdown() bool {
+	return t.Target > 0
+}
+
+// Remaining returns the time left until the target is reached. It is negative once the target is overrun.
+func (t *Timer) Remaining(nowProviderArg ...NowProvider) time.Duration {
+	return t.Target - t.Elapsed(nowProviderArg...)
+}
+
+// IsExpired reports whether a countdown has run past its target. One that is exactly due isn't expired yet.
+func (t *Timer) IsExpired(nowProviderArg ...NowProvider) bool {
+	return t.IsCountdown() && t.Remaining(nowProviderArg...) < 0
+}
+
+func (t *Timer) RemainingString(nowProviderArg ...NowProvider) string {
+	return t.RoundedRemainingString(Precision, nowProviderArg...)
+}
+
+// RoundedRemainingString describes the time left on a countdown, rounded to precision. It reads as overrun
+// exactly when the countdown IsExpired, however the time rounds.
+func (t *Timer) RoundedRemainingString(precision time.Duration, nowProviderArg ...NowProvider) string {
+	remaining := t.Remaining(nowProviderArg...)
+	if remaining < 0 {
+		return fmt.Sprintf("%s overrun", (-remaining).Round(precision))
+	}
+	return fmt.Sprintf("%s remaining", remaining.Round(precision))
 }
 
 func allTimerFiles(cacheDir string) ([]os.DirEntry, error) {
@@ -135,8 +333,27 @@ func allTimerFiles(cacheDir string) ([]os.DirEntry, error) {
 	return matchFiles, nil
 }
 
+// timerPath returns the file that keeps a timer. A timer whose name has characters that are now encoded may
+// still be in a file named before encoding was introduced, and that file is used as long as it is there.
+func timerPath(name string, cacheDir string) string {
+	path := filepath.Join(cacheDir, EncodeName(name) + ".json")
+	if EncodeName(name) == name || nameFromFile(name) != name {
+		return path
+	}
+
+	legacy := filepath.Join(cacheDir, name + ".json")
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return path
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return path
+	}
+	slog.Debug("Using timer file from before names were encoded", "path", legacy)
+	return legacy
+}
+
 func Clear(name string, cacheDir string) error {
-	path := filepath.Join(cacheDir, name + ".json")
+	path := timerPath(name, cacheDir)
 	slog.Debug("Clearing timer file", "path", path)
 
 	err := os.Remove(path)
@@ -145,6 +362,7 @@ func Clear(name string, cacheDir string) error {
 		slog.Error(msg, "error", err)
 		return fmt.Errorf(msg + ": %v", err)
 	}
+	removeLockFile(lockFilePath(name, cacheDir))
 	return nil
 }
 
@@ -161,7 +379,9 @@ func ClearAll(cacheDir string) error {
 		if err != nil {
 			slog.Error("Couldn't remove timer file!", "path", path, "error", err)
 			failures = append(failures, file)
+			continue
 		}
+		removeLockFile(lockFilePath(nameFromFile(strings.TrimSuffix(file.Name(), ".json")), cacheDir))
 	}
 
 	if len(failures) > 0 {
@@ -172,7 +392,7 @@ func ClearAll(cacheDir string) error {
 }
 
 func Load(name string, cacheDir string, mustExist ...bool) (*Timer, error) {
-	path := filepath.Join(cacheDir, name + ".json")
+	path := timerPath(name, cacheDir)
 	slog.Debug("Loading timer from file", "path", path)
 
 	t := new(Timer)
@@ -208,7 +428,7 @@ func LoadAll(cacheDir string) ([]*NamedTimer, error) {
 	for _, file := range allFiles {
 		filename := file.Name()
 		ext := filepath.Ext(filename)
-		name := strings.TrimSuffix(filename, ext)
+		name := nameFromFile(strings.TrimSuffix(filename, ext))
 
 		ticks, err := Load(name, cacheDir, true)
 		if err != nil {
@@ -229,7 +449,7 @@ func LoadAll(cacheDir string) ([]*NamedTimer, error) {
 }
 
 func (t *Timer) Dump(name string, cacheDir string) error {
-	path := filepath.Join(cacheDir, name + ".json")
+	path := timerPath(name, cacheDir)
 
 	slog.Debug("Serializing data")
 	data, err := json.Marshal(t)
@@ -240,7 +460,7 @@ func (t *Timer) Dump(name string, cacheDir string) error {
 	}
 
 	slog.Debug("Dumping timer to file", "path", path)
-	err = os.WriteFile(path, data, 0644)
+	err = writeFileAtomic(path, data)
 	if err != nil {
 		msg := "Error writing timer data to file"
 		slog.Error(msg, "error", err)
@@ -250,49 +470,151 @@ func (t *Timer) Dump(name string, cacheDir string) error {
 	return nil
 }
 
+func (t *Timer) Current() *Segment {
+	if len(t.Segments) == 0 {
+		return nil
+	}
+	last := &t.Segments[len(t.Segments) - 1]
+	if !last.IsOpen() {
+		return nil
+	}
+	return last
+}
+
 func (t *Timer) IsRunning() bool {
-	return !t.StartTime.IsZero() && t.EndTime.IsZero()
+	return t.Current() != nil
 }
 
+// lastEnd returns the latest moment covered by a recorded interval, ignoring manual adjustments.
+func (t *Timer) lastEnd() time.Time {
+	var end time.Time
+	for i := range t.Segments {
+		if !t.Segments[i].IsAdjustment() && t.Segments[i].End.After(end) {
+			end = t.Segments[i].End
+		}
+	}
+	return end
+}
+
+// Start opens a new segment. It refuses to start before the end of an interval that is already recorded,
+// which can only happen when starting at a backdated moment.
 func (t *Timer) Start(nowProviderArg ...NowProvider) error {
 	if t.IsRunning() {
 		return fmt.Errorf("Timer is already running")
 	}
 
-	t.StartTime = now(nowProviderArg)
-	t.EndTime = time.Time{}
+	moment := now(nowProviderArg)
+	if end := t.lastEnd(); moment.Before(end) {
+		return fmt.Errorf(
+			"Can't start the timer at %s: it overlaps the previous interval, which ended at %s",
+			moment.Format(time.RFC3339),
+			end.Format(time.RFC3339),
+		)
+	}
+
+	t.Segments = append(t.Segments, Segment{Start: moment})
 	return nil
 }
 
+// Stop closes the running segment. It refuses to stop before the segment started.
 func (t *Timer) Stop(nowProviderArg ...NowProvider) error {
-	if !t.IsRunning() {
+	current := t.Current()
+	if current == nil {
 		return fmt.Errorf("Timer is not running")
 	}
 
-	t.EndTime = now(nowProviderArg)
-	t.TotalTime += t.EndTime.Sub(t.StartTime)
+	moment := now(nowProviderArg)
+	if moment.Before(current.Start) {
+		return fmt.Errorf(
+			"Can't stop the timer at %s: it was started at %s",
+			moment.Format(time.RFC3339),
+			current.Start.Format(time.RFC3339),
+		)
+	}
+
+	current.End = moment
+	current.Duration = current.End.Sub(current.Start)
 	return nil
 }
 
-func (t *Timer) Toggle(nowProviderArg ...NowProvider) bool {
-	wasStopped := false
+// Toggle stops a running timer or starts a stopped one, and reports whether it stopped the timer. It fails
+// like Start and Stop do when the moment would overlap the timer's recorded time.
+func (t *Timer) Toggle(nowProviderArg ...NowProvider) (bool, error) {
 	if t.IsRunning() {
-		err := t.Stop(nowProviderArg...)
-		if err != nil {
-			panic("Error stopping timer (this should not happen): " + err.Error())
-		}
-		wasStopped = true
-	} else {
-		err := t.Start(nowProviderArg...)
-		if err != nil {
-			panic("Error starting timer (this should not happen): " + err.Error())
-		}
+		return true, t.Stop(nowProviderArg...)
+	}
+	return false, t.Start(nowProviderArg...)
+}
+
+func (t *Timer) Lap(name string, nowProviderArg ...NowProvider) (*Lap, error) {
+	if !t.IsRunning() {
+		return nil, fmt.Errorf("Timer is not running")
+	}
+
+	moment := now(nowProviderArg)
+	t.Laps = append(
+		t.Laps,
+		Lap{
+			Name: name,
+			Time: moment,
+			Elapsed: t.Elapsed(FixedNowProvider{Moment: moment}),
+		},
+	)
+	return &t.Laps[len(t.Laps) - 1], nil
+}
+
+// Split returns the time between the lap at index i and the lap before it.
+func (t *Timer) Split(i int) time.Duration {
+	if i == 0 {
+		return t.Laps[0].Elapsed
 	}
-	return wasStopped
+	return t.Laps[i].Elapsed - t.Laps[i - 1].Elapsed
+}
+
+func (t *Timer) LapString(i int) string {
+	label := fmt.Sprintf("Lap %d", i + 1)
+	if t.Laps[i].Name != "" {
+		label += " (" + t.Laps[i].Name + ")"
+	}
+	text := fmt.Sprintf(
+		"%s: %s (total %s)",
+		label,
+		t.Split(i).Round(Precision),
+		t.Laps[i].Elapsed.Round(Precision),
+	)
+	if t.Laps[i].Note != "" {
+		text += ": " + t.Laps[i].Note
+	}
+	return text
+}
+
+// SetNote replaces the note on the segment at index i. Negative indexes count back from the last segment.
+func (t *Timer) SetNote(i int, note string) error {
+	index := i
+	if i < 0 {
+		i += len(t.Segments)
+	}
+	if i < 0 || i >= len(t.Segments) {
+		return fmt.Errorf("Segment index %d is out of range", index)
+	}
+	t.Segments[i].Note = note
+	return nil
+}
+
+// SetLapNote replaces the note on the lap at index i. Negative indexes count back from the last lap.
+func (t *Timer) SetLapNote(i int, note string) error {
+	index := i
+	if i < 0 {
+		i += len(t.Laps)
+	}
+	if i < 0 || i >= len(t.Laps) {
+		return fmt.Errorf("Lap index %d is out of range", index)
+	}
+	t.Laps[i].Note = note
+	return nil
 }
 
 func (t *Timer) Reset() {
-	t.StartTime = time.Time{}
-	t.EndTime = time.Time{}
-	t.TotalTime = 0
+	t.Segments = nil
+	t.Laps = nil
 }
@@ -43,6 +43,15 @@ func moment(m string) time.Time {
 	return t
 }
 
+func segment(start string, end string) timer.Segment {
+	s := timer.Segment{Start: moment(start)}
+	if end != "" {
+		s.End = moment(end)
+		s.Duration = s.End.Sub(s.Start)
+	}
+	return s
+}
+
 func TestGetConfigDir(t *testing.T) {
 	want := filepath.Join(home(), "/.config", timer.APP_NAME)
 	got := timer.GetConfigDir()
@@ -124,9 +133,10 @@ func TestLoad_ValidFile(t *testing.T) {
 	cacheDir := t.TempDir()
 
 	want := &timer.Timer{
-		TotalTime:	span("3m"),
-		StartTime:	moment("2025-03-11T11:02:00Z"),
-		EndTime:	moment("2025-03-11T11:05:00Z"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T10:55:00Z", "2025-03-11T10:58:00Z"),
+			segment("2025-03-11T11:02:00Z", "2025-03-11T11:05:00Z"),
+		},
 	}
 	data, err := json.Marshal(want)
 	if err != nil {
@@ -147,6 +157,61 @@ func TestLoad_ValidFile(t *testing.T) {
 	}
 }
 
+func TestLoad_LegacyFile(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	cases := map[string]struct {
+		data	string
+		want	*timer.Timer
+	}{
+		"stopped": {
+			data: `{"total":300000000000,"start":"2025-03-11T11:02:00Z","end":"2025-03-11T11:05:00Z"}`,
+			want: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-03-11T11:00:00Z", "2025-03-11T11:02:00Z"),
+					segment("2025-03-11T11:02:00Z", "2025-03-11T11:05:00Z"),
+				},
+			},
+		},
+		"running": {
+			data: `{"total":120000000000,"start":"2025-03-11T11:02:00Z","end":"0001-01-01T00:00:00Z"}`,
+			want: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-03-11T11:00:00Z", "2025-03-11T11:02:00Z"),
+					segment("2025-03-11T11:02:00Z", ""),
+				},
+			},
+		},
+		"single": {
+			data: `{"total":180000000000,"start":"2025-03-11T11:02:00Z","end":"2025-03-11T11:05:00Z"}`,
+			want: &timer.Timer{
+				Segments: []timer.Segment{
+					segment("2025-03-11T11:02:00Z", "2025-03-11T11:05:00Z"),
+				},
+			},
+		},
+		"empty": {
+			data: `{"total":0,"start":"0001-01-01T00:00:00Z","end":"0001-01-01T00:00:00Z"}`,
+			want: &timer.Timer{},
+		},
+	}
+
+	for name, c := range cases {
+		path := filepath.Join(cacheDir, name + ".json")
+		err := os.WriteFile(path, []byte(c.data), 0644)
+		if err != nil {
+			t.Fatalf("Couldn't write legacy watch file: %v", err)
+		}
+
+		got, err := timer.Load(name, cacheDir)
+		if err != nil {
+			t.Errorf("Load returned an error for legacy watch file %v: %v", name, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Load returned wrong timer for legacy watch %v: wanted %v, got %v", name, c.want, got)
+		}
+	}
+}
+
 func TestLoadAll_BadDir(t *testing.T) {
 	_, err := timer.LoadAll("nonexistent")
 	if err == nil {
@@ -158,9 +223,9 @@ func TestLoadAll_Valid(t *testing.T) {
 	cacheDir := t.TempDir()
 
 	timer1 := &timer.Timer{
-		TotalTime:	span("1m"),
-		StartTime:	moment("2025-03-11T17:39:00Z"),
-		EndTime:	moment("2025-03-11T17:40:00Z"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T17:39:00Z", "2025-03-11T17:40:00Z"),
+		},
 	}
 	err := timer1.Dump("good1", cacheDir)
 	if err != nil {
@@ -168,9 +233,9 @@ func TestLoadAll_Valid(t *testing.T) {
 	}
 
 	timer2 := &timer.Timer{
-		TotalTime:	span("2m"),
-		StartTime:	moment("2025-03-11T17:40:00Z"),
-		EndTime:	moment("2025-03-11T17:42:00Z"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T17:40:00Z", "2025-03-11T17:42:00Z"),
+		},
 	}
 	err = timer2.Dump("good2", cacheDir)
 	if err != nil {
@@ -178,9 +243,9 @@ func TestLoadAll_Valid(t *testing.T) {
 	}
 
 	timer3 := &timer.Timer{
-		TotalTime:	span("3m"),
-		StartTime:	moment("2025-03-11T17:42:00Z"),
-		EndTime:	moment("2025-03-11T17:45:00Z"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T17:42:00Z", "2025-03-11T17:45:00Z"),
+		},
 	}
 	err = timer3.Dump("good3", cacheDir)
 	if err != nil {
@@ -194,9 +259,9 @@ func TestLoadAll_Valid(t *testing.T) {
 	}
 
 	timer4 := &timer.Timer{
-		TotalTime:	span("4m"),
-		StartTime:	moment("2025-03-11T17:45:00Z"),
-		EndTime:	moment("2025-03-11T17:49:00Z"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T17:45:00Z", "2025-03-11T17:49:00Z"),
+		},
 	}
 	err = timer4.Dump("good4", subDir)
 	if err != nil {
@@ -235,19 +300,30 @@ func TestLoadAll_Valid(t *testing.T) {
 }
 
 func TestDump_WriteFileError(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "missing")
+
+	ticks := new(timer.Timer)
+	err := ticks.Dump("forbidden", cacheDir)
+	if err == nil {
+		t.Errorf("Dump watch didn't return an error when the cache dir doesn't exist")
+	}
+}
+
+func TestDump_NoTempFiles(t *testing.T) {
 	cacheDir := t.TempDir()
 
-	dummyData := []byte("dummy data")
-	path := filepath.Join(cacheDir, "forbidden.json")
-	err := os.WriteFile(path, dummyData, 0000)
+	ticks := new(timer.Timer)
+	err := ticks.Dump("valid", cacheDir)
 	if err != nil {
-		t.Fatalf("Couldn't write dummy watch file: %v", err)
+		t.Fatalf("Dump returned an error for a valid watch file: %v", err)
 	}
 
-	ticks := new(timer.Timer)
-	err = ticks.Dump("forbidden", cacheDir)
-	if err == nil {
-		t.Errorf("Dump watch didn't return an error on a protected file")
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("Couldn't read cache dir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "valid.json" {
+		t.Errorf("Dump left extra files in the cache dir: %v", entries)
 	}
 }
 
@@ -255,9 +331,10 @@ func TestDump_ValidFile(t *testing.T) {
 	cacheDir := t.TempDir()
 
 	want := &timer.Timer{
-		TotalTime:	span("3m"),
-		StartTime:	moment("2025-03-11T11:02:00Z"),
-		EndTime:	moment("2025-03-11T11:05:00Z"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T11:02:00Z", "2025-03-11T11:05:00Z"),
+			segment("2025-03-11T11:07:00Z", ""),
+		},
 	}
 
 	err := want.Dump("valid", cacheDir)
@@ -289,14 +366,14 @@ func TestIsRunning(t *testing.T) {
 		t.Errorf("IsRunning returned true on a timer with no start time")
 	}
 
-	ticks.StartTime = moment("2025-03-11T11:47:00Z")
+	ticks.Segments = []timer.Segment{segment("2025-03-11T11:47:00Z", "")}
 	if !ticks.IsRunning() {
-		t.Errorf("IsRunning returned false on a timer with only a start time")
+		t.Errorf("IsRunning returned false on a timer with an open segment")
 	}
 
-	ticks.EndTime = moment("2025-03-11T11:49:00Z")
+	ticks.Segments = []timer.Segment{segment("2025-03-11T11:47:00Z", "2025-03-11T11:49:00Z")}
 	if ticks.IsRunning() {
-		t.Errorf("IsRunning returned true on a timer with a start time and end time")
+		t.Errorf("IsRunning returned true on a timer with only closed segments")
 	}
 }
 
@@ -320,10 +397,10 @@ func TestStart(t *testing.T) {
 		t.Errorf("Start returned an error even though timer is not running: %v", err)
 	}
 
-	got := ticks.StartTime
-	want := np.Moment
-	if want != got {
-		t.Errorf("Start didn't set the start time correctly: wanted %v, got %v", want, got)
+	want := []timer.Segment{{Start: np.Moment}}
+	got := ticks.Segments
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Start didn't open a segment correctly: wanted %v, got %v", want, got)
 	}
 
 	err = ticks.Start(np)
@@ -334,8 +411,10 @@ func TestStart(t *testing.T) {
 
 func TestStop(t *testing.T) {
 	ticks := &timer.Timer{
-		TotalTime: span("3m"),
-		StartTime: moment("2025-03-11T12:27:00Z"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T12:20:00Z", "2025-03-11T12:23:00Z"),
+			segment("2025-03-11T12:27:00Z", ""),
+		},
 	}
 
 	np := freeze(t, "2025-03-11T12:29:00Z")
@@ -345,16 +424,16 @@ func TestStop(t *testing.T) {
 		t.Errorf("Stop returned an error even though timer is running: %v", err)
 	}
 
-	got := ticks.EndTime
-	want := np.Moment
+	want := segment("2025-03-11T12:27:00Z", "2025-03-11T12:29:00Z")
+	got := ticks.Segments[1]
 	if want != got {
-		t.Errorf("Stop didn't set the end time correctly: wanted %v, got %v", want, got)
+		t.Errorf("Stop didn't close the segment correctly: wanted %v, got %v", want, got)
 	}
 
 	wantTotal := span("5m")
-	gotTotal := ticks.TotalTime
+	gotTotal := ticks.Elapsed(np)
 	if wantTotal != gotTotal {
-		t.Errorf("Stop didn't update total time correctly: wanted %v, got %v", want, got)
+		t.Errorf("Stop didn't update elapsed time correctly: wanted %v, got %v", wantTotal, gotTotal)
 	}
 
 	err = ticks.Stop(np)
@@ -363,72 +442,309 @@ func TestStop(t *testing.T) {
 	}
 }
 
+func TestStart_Backdated(t *testing.T) {
+	ticks := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T12:20:00Z", "2025-03-11T12:23:00Z"),
+			{
+				Start: moment("2025-03-11T12:40:00Z"),
+				End: moment("2025-03-11T12:40:00Z"),
+				Duration: span("1h"),
+				Kind: timer.MANUAL,
+			},
+		},
+	}
+
+	err := ticks.Start(freeze(t, "2025-03-11T12:22:00Z"))
+	if err == nil {
+		t.Errorf("Start didn't refuse to overlap the previous interval")
+	}
+
+	err = ticks.Start(freeze(t, "2025-03-11T12:23:00Z"))
+	if err != nil {
+		t.Errorf("Start refused to start where the previous interval ended: %v", err)
+	}
+
+	err = ticks.Stop(freeze(t, "2025-03-11T12:21:00Z"))
+	if err == nil {
+		t.Errorf("Stop didn't refuse to stop before the timer started")
+	}
+	if !ticks.IsRunning() {
+		t.Errorf("Stop closed the segment even though it returned an error")
+	}
+}
+
 func TestToggle(t *testing.T) {
 	ticks := new(timer.Timer)
 
 	np := freeze(t, "2025-03-11T12:42:00Z")
-	m1 := np.Moment
-	st := ticks.Toggle(np)
+	st, err := ticks.Toggle(np)
+	if err != nil {
+		t.Fatalf("Toggle returned an error: %v", err)
+	}
 	if st {
 		t.Errorf("Toggle reported a stoppage on a timer that wasn't running")
 	}
 	want := &timer.Timer{
-		StartTime: m1,
+		Segments: []timer.Segment{
+			segment("2025-03-11T12:42:00Z", ""),
+		},
 	}
 	if !reflect.DeepEqual(want, ticks) {
 		t.Errorf("Toggle didn't update timer correctly: wanted %v, got %v", want, ticks)
 	}
 
 	np = freeze(t, "2025-03-11T12:47:00Z")
-	m2 := np.Moment
-	st = ticks.Toggle(np)
+	st, err = ticks.Toggle(np)
+	if err != nil {
+		t.Fatalf("Toggle returned an error: %v", err)
+	}
 	if !st {
 		t.Errorf("Toggle reported no stoppage on a timer that was running")
 	}
 	want = &timer.Timer{
-		StartTime: m1,
-		EndTime: m2,
-		TotalTime: span("5m"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T12:42:00Z", "2025-03-11T12:47:00Z"),
+		},
 	}
 	if !reflect.DeepEqual(want, ticks) {
 		t.Errorf("Toggle didn't update timer correctly: wanted %v, got %v", want, ticks)
 	}
 
 	np = freeze(t, "2025-03-11T12:56:00Z")
-	m3 := np.Moment
-	st = ticks.Toggle(np)
+	st, err = ticks.Toggle(np)
+	if err != nil {
+		t.Fatalf("Toggle returned an error: %v", err)
+	}
 	if st {
 		t.Errorf("Toggle reported a stoppage on a timer that wasn't running")
 	}
 	want = &timer.Timer{
-		StartTime: m3,
-		TotalTime: span("5m"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T12:42:00Z", "2025-03-11T12:47:00Z"),
+			segment("2025-03-11T12:56:00Z", ""),
+		},
 	}
 	if !reflect.DeepEqual(want, ticks) {
 		t.Errorf("Toggle didn't update timer correctly: wanted %v, got %v", want, ticks)
 	}
 
 	np = freeze(t, "2025-03-11T12:57:00Z")
-	m4 := np.Moment
-	st = ticks.Toggle(np)
+	st, err = ticks.Toggle(np)
+	if err != nil {
+		t.Fatalf("Toggle returned an error: %v", err)
+	}
 	if !st {
 		t.Errorf("Toggle reported no stoppage on a timer that was running")
 	}
 	want = &timer.Timer{
-		StartTime: m3,
-		EndTime: m4,
-		TotalTime: span("6m"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T12:42:00Z", "2025-03-11T12:47:00Z"),
+			segment("2025-03-11T12:56:00Z", "2025-03-11T12:57:00Z"),
+		},
 	}
 	if !reflect.DeepEqual(want, ticks) {
 		t.Errorf("Toggle didn't update timer correctly: wanted %v, got %v", want, ticks)
 	}
+	if ticks.Elapsed(np) != span("6m") {
+		t.Errorf("Toggle didn't accumulate elapsed time correctly: wanted %v, got %v", span("6m"), ticks.Elapsed(np))
+	}
+}
+
+func TestToggle_Overlap(t *testing.T) {
+	ticks := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T12:00:00Z", "2025-03-11T13:00:00Z"),
+		},
+	}
+
+	// A start before the last interval ended, like after importing intervals from the future.
+	_, err := ticks.Toggle(freeze(t, "2025-03-11T12:30:00Z"))
+	if err == nil {
+		t.Errorf("Toggle didn't refuse to start inside the previous interval")
+	}
+	if ticks.IsRunning() {
+		t.Errorf("Toggle started the timer even though it returned an error")
+	}
+}
+
+func TestElapsed_Running(t *testing.T) {
+	ticks := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T13:00:00Z", "2025-03-11T13:04:00Z"),
+			segment("2025-03-11T13:10:00Z", ""),
+		},
+	}
+
+	np := freeze(t, "2025-03-11T13:12:00Z")
+	want := span("6m")
+	got := ticks.Elapsed(np)
+	if want != got {
+		t.Errorf("Elapsed didn't include the open segment: wanted %v, got %v", want, got)
+	}
+}
+
+func TestLap(t *testing.T) {
+	ticks := new(timer.Timer)
+
+	_, err := ticks.Lap("", freeze(t, "2025-03-11T13:00:00Z"))
+	if err == nil {
+		t.Errorf("Lap didn't return an error even though timer is not running")
+	}
+
+	_ = ticks.Start(freeze(t, "2025-03-11T13:00:00Z"))
+
+	lap, err := ticks.Lap("", freeze(t, "2025-03-11T13:02:00Z"))
+	if err != nil {
+		t.Fatalf("Lap returned an error even though timer is running: %v", err)
+	}
+	if lap.Elapsed != span("2m") {
+		t.Errorf("Lap recorded the wrong elapsed time: wanted %v, got %v", span("2m"), lap.Elapsed)
+	}
+
+	_ = ticks.Stop(freeze(t, "2025-03-11T13:03:00Z"))
+	_ = ticks.Start(freeze(t, "2025-03-11T13:10:00Z"))
+
+	_, err = ticks.Lap("second", freeze(t, "2025-03-11T13:12:00Z"))
+	if err != nil {
+		t.Fatalf("Lap returned an error even though timer is running: %v", err)
+	}
+
+	want := []timer.Lap{
+		{Time: moment("2025-03-11T13:02:00Z"), Elapsed: span("2m")},
+		{Name: "second", Time: moment("2025-03-11T13:12:00Z"), Elapsed: span("5m")},
+	}
+	if !reflect.DeepEqual(want, ticks.Laps) {
+		t.Errorf("Lap didn't record laps correctly: wanted %v, got %v", want, ticks.Laps)
+	}
+
+	if ticks.Split(1) != span("3m") {
+		t.Errorf("Split returned the wrong time: wanted %v, got %v", span("3m"), ticks.Split(1))
+	}
+
+	wantString := "Lap 2 (second): 3m0s (total 5m0s)"
+	if ticks.LapString(1) != wantString {
+		t.Errorf("LapString returned the wrong string: wanted %v, got %v", wantString, ticks.LapString(1))
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	ticks := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T14:00:00Z", "2025-03-11T14:10:00Z"),
+			segment("2025-03-11T14:20:00Z", ""),
+		},
+		Target: span("25m"),
+	}
+
+	np := freeze(t, "2025-03-11T14:30:00Z")
+	if ticks.Remaining(np) != span("5m") {
+		t.Errorf("Remaining returned the wrong time: wanted %v, got %v", span("5m"), ticks.Remaining(np))
+	}
+	if ticks.IsExpired(np) {
+		t.Errorf("IsExpired returned true before the target was reached")
+	}
+	if ticks.RemainingString(np) != "5m0s remaining" {
+		t.Errorf("RemainingString returned the wrong string: got %v", ticks.RemainingString(np))
+	}
+
+	np = freeze(t, "2025-03-11T14:35:00Z")
+	if ticks.IsExpired(np) {
+		t.Errorf("IsExpired returned true when the target was just reached")
+	}
+	if ticks.RemainingString(np) != "0s remaining" {
+		t.Errorf("RemainingString returned the wrong string when due: got %v", ticks.RemainingString(np))
+	}
+
+	np = freeze(t, "2025-03-11T14:35:00.2Z")
+	if !ticks.IsExpired(np) || ticks.RoundedRemainingString(time.Second, np) != "0s overrun" {
+		t.Errorf("RoundedRemainingString didn't agree with IsExpired: got %v", ticks.RoundedRemainingString(time.Second, np))
+	}
+
+	np = freeze(t, "2025-03-11T14:37:00Z")
+	if ticks.Remaining(np) != -span("2m") {
+		t.Errorf("Remaining returned the wrong overrun: wanted %v, got %v", -span("2m"), ticks.Remaining(np))
+	}
+	if !ticks.IsExpired(np) {
+		t.Errorf("IsExpired returned false after the target was overrun")
+	}
+	if ticks.RemainingString(np) != "2m0s overrun" {
+		t.Errorf("RemainingString returned the wrong string: got %v", ticks.RemainingString(np))
+	}
+
+	ticks.Target = 0
+	if ticks.IsExpired(np) {
+		t.Errorf("IsExpired returned true on a timer without a target")
+	}
+}
+
+func TestSetNote(t *testing.T) {
+	ticks := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T14:00:00Z", "2025-03-11T14:10:00Z"),
+			segment("2025-03-11T14:20:00Z", ""),
+		},
+	}
+
+	ticks.Segments[1].AddNote("started")
+	ticks.Segments[1].AddNote("stopped")
+	if ticks.Segments[1].Note != "started; stopped" {
+		t.Errorf("AddNote didn't append the note: got %q", ticks.Segments[1].Note)
+	}
+
+	err := ticks.SetNote(0, "first")
+	if err != nil {
+		t.Errorf("SetNote returned an error for a valid index: %v", err)
+	}
+	err = ticks.SetNote(-1, "last")
+	if err != nil {
+		t.Errorf("SetNote returned an error for a negative index: %v", err)
+	}
+	if ticks.Segments[0].Note != "first" || ticks.Segments[1].Note != "last" {
+		t.Errorf("SetNote didn't replace the notes: got %v", ticks.Segments)
+	}
+
+	for i, want := range map[int]string{2: "Segment index 2 is out of range", -3: "Segment index -3 is out of range"} {
+		err = ticks.SetNote(i, "nope")
+		if err == nil || err.Error() != want {
+			t.Errorf("SetNote didn't return the right error for index %v: wanted %q, got %v", i, want, err)
+		}
+	}
+
+	want := "(2025-03-11T14:00:00Z -- 2025-03-11T14:10:00Z) -> 10m0s: first"
+	if ticks.Segments[0].String() != want {
+		t.Errorf("Segment string didn't include the note: wanted %v, got %v", want, ticks.Segments[0].String())
+	}
+}
+
+func TestRecordExit(t *testing.T) {
+	s := segment("2025-03-11T14:00:00Z", "2025-03-11T14:10:00Z")
+	s.RecordExit("make build", 0)
+
+	want := "(2025-03-11T14:00:00Z -- 2025-03-11T14:10:00Z) -> 10m0s [make build exited 0]"
+	if s.String() != want {
+		t.Errorf("Segment string didn't include the command: wanted %v, got %v", want, s.String())
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Couldn't marshal segment: %v", err)
+	}
+	got := timer.Segment{}
+	err = json.Unmarshal(data, &got)
+	if err != nil {
+		t.Fatalf("Couldn't unmarshal segment: %v", err)
+	}
+	if got.Command != "make build" || got.ExitCode == nil || *got.ExitCode != 0 {
+		t.Errorf("Segment didn't keep a zero exit code: got %v", got)
+	}
 }
 
 func TestReset(t *testing.T) {
 	ticks := &timer.Timer{
-		TotalTime:	span("3m"),
-		StartTime:	moment("2025-03-11T11:02:00Z"),
-		EndTime:	moment("2025-03-11T11:05:00Z"),
+		Segments: []timer.Segment{
+			segment("2025-03-11T11:02:00Z", "2025-03-11T11:05:00Z"),
+		},
 	}
 	ticks.Reset()
 
@@ -437,3 +753,40 @@ func TestReset(t *testing.T) {
 		t.Errorf("Reset didn't clear timer correctly: wanted %v, got %v", want, ticks)
 	}
 }
+
+func TestSegment_Clip(t *testing.T) {
+	since := moment("2025-03-11T09:00:00Z")
+	until := moment("2025-03-11T12:00:00Z")
+	now := moment("2025-03-11T11:00:00Z")
+	adjustment := timer.Segment{
+		Start: moment("2025-03-11T10:00:00Z"),
+		End: moment("2025-03-11T10:00:00Z"),
+		Duration: span("-5m"),
+		Kind: timer.MANUAL,
+	}
+
+	tests := []struct {
+		name	string
+		segment	timer.Segment
+		until	time.Time
+		want	timer.Segment
+		ok		bool
+	}{
+		{"inside", segment("2025-03-11T09:30:00Z", "2025-03-11T10:00:00Z"), until, segment("2025-03-11T09:30:00Z", "2025-03-11T10:00:00Z"), true},
+		{"before", segment("2025-03-11T08:00:00Z", "2025-03-11T09:00:00Z"), until, timer.Segment{}, false},
+		{"across since", segment("2025-03-11T08:00:00Z", "2025-03-11T10:00:00Z"), until, segment("2025-03-11T09:00:00Z", "2025-03-11T10:00:00Z"), true},
+		{"across until", segment("2025-03-11T11:00:00Z", "2025-03-11T13:00:00Z"), until, segment("2025-03-11T11:00:00Z", "2025-03-11T12:00:00Z"), true},
+		{"running", segment("2025-03-11T10:00:00Z", ""), until, timer.Segment{Start: moment("2025-03-11T10:00:00Z"), Duration: span("1h")}, true},
+		{"running past until", segment("2025-03-11T10:00:00Z", ""), moment("2025-03-11T10:30:00Z"), segment("2025-03-11T10:00:00Z", "2025-03-11T10:30:00Z"), true},
+		{"adjustment", adjustment, until, adjustment, true},
+		{"adjustment after until", adjustment, moment("2025-03-11T10:00:00Z"), timer.Segment{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := test.segment.Clip(since, test.until, now)
+			if ok != test.ok || !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Clip returned the wrong segment: wanted %v (%v), got %v (%v)", test.want, test.ok, got, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package timer
+
+import (
+	"fmt"
+	"slices"
+)
+
+// lockNames locks every name in sorted order, so that commands touching the same timers can't deadlock.
+func lockNames(s Store, names ...string) (func(), error) {
+	names = slices.Clone(names)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	locks := make([]Unlocker, 0, len(names))
+	unlock := func() {
+		for i := len(locks) - 1; i >= 0; i-- {
+			_ = locks[i].Unlock()
+		}
+	}
+	for _, name := range names {
+		lock, err := s.Lock(name)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+	return unlock, nil
+}
+
+// loadSource loads a timer that is about to be copied or moved, refusing timers that are missing or running.
+func loadSource(s Store, name string) (*Timer, error) {
+	exists, err := s.Exists(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("Timer %v does not exist", name)
+	}
+
+	t, err := s.Load(name, true)
+	if err != nil {
+		return nil, err
+	}
+	if t.IsRunning() {
+		return nil, fmt.Errorf("Timer %v is running; stop it first", name)
+	}
+	return t, nil
+}
+
+// checkDestination refuses to overwrite an existing timer unless forced.
+func checkDestination(s Store, name string, force bool) error {
+	err := CheckName(name)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.Exists(name)
+	if err != nil {
+		return err
+	}
+	if exists && !force {
+		return fmt.Errorf("Timer %v already exists; use --force to replace it", name)
+	}
+	return nil
+}
+
+// Copy duplicates the timer from into to.
+func Copy(s Store, from string, to string, force bool) error {
+	if from == to {
+		return fmt.Errorf("Can't copy timer %v onto itself", from)
+	}
+
+	unlock, err := lockNames(s, from, to)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	t, err := loadSource(s, from)
+	if err != nil {
+		return err
+	}
+	err = checkDestination(s, to, force)
+	if err != nil {
+		return err
+	}
+
+	return s.Dump(to, t)
+}
+
+// Rename moves the timer from to the name to, and points the state at its new name.
+func Rename(s Store, from string, to string, force bool) error {
+	if from == to {
+		return fmt.Errorf("Can't rename timer %v to itself", from)
+	}
+
+	unlock, err := lockNames(s, from, to)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	t, err := loadSource(s, from)
+	if err != nil {
+		return err
+	}
+	err = checkDestination(s, to, force)
+	if err != nil {
+		return err
+	}
+
+	err = s.Dump(to, t)
+	if err != nil {
+		return err
+	}
+	err = s.Clear(from)
+	if err != nil {
+		return err
+	}
+	return s.UpdateState(func(state *State) {
+		state.Forget(to)
+		state.Rename(from, to)
+	})
+}
+
+// MergeTimers combines the intervals and tags of stopped timers into a new timer. Laps are dropped because
+// their totals only make sense for the timer they were recorded on. The first countdown target found is kept.
+func MergeTimers(timers ...*Timer) *Timer {
+	merged := &Timer{}
+	for _, t := range timers {
+		merged.Segments = append(merged.Segments, t.Segments...)
+		for _, tag := range t.Tags {
+			if !merged.HasTag(tag) {
+				merged.Tags = append(merged.Tags, tag)
+			}
+		}
+		if merged.Target == 0 {
+			merged.Target = t.Target
+		}
+	}
+	slices.SortStableFunc(merged.Segments, func(a Segment, b Segment) int {
+		return a.Start.Compare(b.Start)
+	})
+	slices.Sort(merged.Tags)
+	return merged
+}
+
+// Merge combines the source timers into the timer named into and clears the sources. The destination may be
+// one of the sources; otherwise it must not exist unless forced, in which case it is replaced. Events in the
+// state that named a source now name the destination.
+func Merge(s Store, sources []string, into string, force bool) error {
+	if len(sources) == 0 {
+		return fmt.Errorf("Give at least one timer to merge")
+	}
+
+	unlock, err := lockNames(s, append(slices.Clone(sources), into)...)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	unique := make([]string, 0, len(sources))
+	for _, name := range sources {
+		if !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+	sources = unique
+
+	timers := make([]*Timer, 0, len(sources))
+	for _, name := range sources {
+		t, err := loadSource(s, name)
+		if err != nil {
+			return err
+		}
+		timers = append(timers, t)
+	}
+	if !slices.Contains(sources, into) {
+		err = checkDestination(s, into, force)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.Dump(into, MergeTimers(timers...))
+	if err != nil {
+		return err
+	}
+	for _, name := range sources {
+		if name != into {
+			err = s.Clear(name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return s.UpdateState(func(state *State) {
+		if !slices.Contains(sources, into) {
+			state.Forget(into)
+		}
+		for _, name := range sources {
+			state.Rename(name, into)
+		}
+	})
+}
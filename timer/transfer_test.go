@@ -0,0 +1,196 @@
+package timer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func transferStore(t *testing.T) timer.Store {
+	s := timer.NewMemoryStore()
+	timers := map[string]*timer.Timer{
+		"one": {
+			Segments: []timer.Segment{segment("2025-03-11T09:00:00Z", "2025-03-11T10:00:00Z")},
+			Tags: []string{"client-a"},
+		},
+		"two": {
+			Segments: []timer.Segment{segment("2025-03-11T08:00:00Z", "2025-03-11T08:30:00Z")},
+			Tags: []string{"meeting"},
+			Target: span("1h"),
+		},
+		"running": {
+			Segments: []timer.Segment{segment("2025-03-11T11:00:00Z", "")},
+		},
+	}
+	for name, ticks := range timers {
+		err := s.Dump(name, ticks)
+		if err != nil {
+			t.Fatalf("Couldn't dump timer: %v", err)
+		}
+	}
+	return s
+}
+
+func exists(t *testing.T, s timer.Store, name string) bool {
+	exists, err := s.Exists(name)
+	if err != nil {
+		t.Fatalf("Exists returned an error: %v", err)
+	}
+	return exists
+}
+
+func TestCopy(t *testing.T) {
+	s := transferStore(t)
+
+	err := timer.Copy(s, "one", "three", false)
+	if err != nil {
+		t.Fatalf("Copy returned an error: %v", err)
+	}
+	original, _ := s.Load("one", true)
+	copied, _ := s.Load("three", true)
+	if !reflect.DeepEqual(original, copied) {
+		t.Errorf("Copy didn't duplicate the timer: wanted %v, got %v", original, copied)
+	}
+
+	err = timer.Copy(s, "one", "two", false)
+	if err == nil {
+		t.Errorf("Copy didn't refuse to overwrite an existing timer")
+	}
+	err = timer.Copy(s, "one", "two", true)
+	if err != nil {
+		t.Errorf("Copy didn't overwrite an existing timer when forced: %v", err)
+	}
+
+	err = timer.Copy(s, "running", "four", false)
+	if err == nil {
+		t.Errorf("Copy didn't refuse to copy a running timer")
+	}
+	err = timer.Copy(s, "missing", "four", false)
+	if err == nil {
+		t.Errorf("Copy didn't refuse to copy a missing timer")
+	}
+}
+
+func TestRename(t *testing.T) {
+	s := transferStore(t)
+
+	err := timer.Rename(s, "one", "acme/one", false)
+	if err != nil {
+		t.Fatalf("Rename returned an error: %v", err)
+	}
+	if exists(t, s, "one") || !exists(t, s, "acme/one") {
+		t.Errorf("Rename didn't move the timer")
+	}
+
+	err = timer.Rename(s, "acme/one", "two", false)
+	if err == nil {
+		t.Errorf("Rename didn't refuse to overwrite an existing timer")
+	}
+	err = timer.Rename(s, "running", "walking", false)
+	if err == nil {
+		t.Errorf("Rename didn't refuse to rename a running timer")
+	}
+	err = timer.Rename(s, "two", "bad//name", false)
+	if err == nil {
+		t.Errorf("Rename didn't refuse an invalid name")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	s := transferStore(t)
+
+	err := timer.Merge(s, []string{"one", "two"}, "both", false)
+	if err != nil {
+		t.Fatalf("Merge returned an error: %v", err)
+	}
+	if exists(t, s, "one") || exists(t, s, "two") {
+		t.Errorf("Merge didn't clear the sources")
+	}
+
+	merged, err := s.Load("both", true)
+	if err != nil {
+		t.Fatalf("Merge didn't create the destination: %v", err)
+	}
+	want := &timer.Timer{
+		Segments: []timer.Segment{
+			segment("2025-03-11T08:00:00Z", "2025-03-11T08:30:00Z"),
+			segment("2025-03-11T09:00:00Z", "2025-03-11T10:00:00Z"),
+		},
+		Tags: []string{"client-a", "meeting"},
+		Target: span("1h"),
+	}
+	if !reflect.DeepEqual(want, merged) {
+		t.Errorf("Merge didn't combine the timers: wanted %v, got %v", want, merged)
+	}
+}
+
+func TestMerge_Conflicts(t *testing.T) {
+	s := transferStore(t)
+
+	err := timer.Merge(s, []string{"one", "two"}, "two", false)
+	if err != nil {
+		t.Errorf("Merge refused to merge into one of its sources: %v", err)
+	}
+
+	err = timer.Copy(s, "two", "one", false)
+	if err != nil {
+		t.Fatalf("Copy returned an error: %v", err)
+	}
+	err = timer.Merge(s, []string{"one"}, "two", false)
+	if err == nil {
+		t.Errorf("Merge didn't refuse to overwrite an existing timer")
+	}
+	err = timer.Merge(s, []string{"one", "running"}, "both", false)
+	if err == nil {
+		t.Errorf("Merge didn't refuse to merge a running timer")
+	}
+	if !exists(t, s, "one") {
+		t.Errorf("Merge cleared a source after failing")
+	}
+}
+
+func TestRename_State(t *testing.T) {
+	s := transferStore(t)
+	_ = s.UpdateState(func(state *timer.State) {
+		state.RecordStart("one", moment("2025-03-11T09:00:00Z"))
+		state.RecordStop("two", moment("2025-03-11T08:30:00Z"))
+	})
+
+	err := timer.Rename(s, "one", "two", true)
+	if err != nil {
+		t.Fatalf("Rename returned an error: %v", err)
+	}
+
+	state, err := s.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState returned an error: %v", err)
+	}
+	if state.Started == nil || state.Started.Name != "two" {
+		t.Errorf("Rename didn't point the last start at the new name: got %v", state.Started)
+	}
+	if state.Stopped != nil {
+		t.Errorf("Rename kept the last stop of the timer it replaced: got %v", state.Stopped)
+	}
+}
+
+func TestMerge_State(t *testing.T) {
+	s := transferStore(t)
+	_ = s.UpdateState(func(state *timer.State) {
+		state.RecordStart("one", moment("2025-03-11T09:00:00Z"))
+		state.RecordStop("two", moment("2025-03-11T08:30:00Z"))
+	})
+
+	err := timer.Merge(s, []string{"one", "two"}, "both", false)
+	if err != nil {
+		t.Fatalf("Merge returned an error: %v", err)
+	}
+
+	state, err := s.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState returned an error: %v", err)
+	}
+	if state.Started == nil || state.Started.Name != "both" || state.Stopped == nil || state.Stopped.Name != "both" {
+		t.Errorf("Merge didn't point the state at the destination: got %v and %v", state.Started, state.Stopped)
+	}
+}
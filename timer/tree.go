@@ -0,0 +1,103 @@
+package timer
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Node is one level of the tree formed by hierarchical timer names. Parents that were never used as timers
+// themselves have no Ticks.
+type Node struct {
+	Name		string
+	Path		string
+	Ticks		*Timer
+	Children	[]*Node
+}
+
+func BuildTree(nts []*NamedTimer) []*Node {
+	root := &Node{}
+	for _, nt := range nts {
+		node := root
+		parts := strings.Split(nt.Name, NAME_SEPARATOR)
+		for i, part := range parts {
+			var child *Node
+			for _, c := range node.Children {
+				if c.Name == part {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &Node{
+					Name: part,
+					Path: strings.Join(parts[:i + 1], NAME_SEPARATOR),
+				}
+				node.Children = append(node.Children, child)
+			}
+			node = child
+		}
+		node.Ticks = nt.Ticks
+	}
+
+	root.sort()
+	return root.Children
+}
+
+func (n *Node) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Name < n.Children[j].Name
+	})
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
+
+// Elapsed rolls up the elapsed time of the node's own timer and all of its descendants.
+func (n *Node) Elapsed(nowProviderArg ...NowProvider) time.Duration {
+	var elapsed time.Duration
+	if n.Ticks != nil {
+		elapsed = n.Ticks.Elapsed(nowProviderArg...)
+	}
+	for _, child := range n.Children {
+		elapsed += child.Elapsed(nowProviderArg...)
+	}
+	return elapsed
+}
+
+func (n *Node) IsRunning() bool {
+	if n.Ticks != nil && n.Ticks.IsRunning() {
+		return true
+	}
+	for _, child := range n.Children {
+		if child.IsRunning() {
+			return true
+		}
+	}
+	return false
+}
+
+// Walk calls fn for the node and each of its descendants, depth first, along with its depth below the node.
+func (n *Node) Walk(fn func(node *Node, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *Node) walk(fn func(node *Node, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth + 1)
+	}
+}
+
+// Find returns the node for a full path, or nil if no timer lives at or below it.
+func Find(nodes []*Node, path string) *Node {
+	for _, node := range nodes {
+		if node.Path == path {
+			return node
+		}
+		if IsWithin(path, node.Path) {
+			return Find(node.Children, path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package timer_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dusktreader/gowatch/timer"
+)
+
+func TestBuildTree(t *testing.T) {
+	stopped := func(minutes int) *timer.Timer {
+		return &timer.Timer{
+			Segments: []timer.Segment{
+				segment("2025-03-11T20:00:00Z", fmt.Sprintf("2025-03-11T20:%02d:00Z", minutes)),
+			},
+		}
+	}
+
+	nts := []*timer.NamedTimer{
+		{Name: "acme/frontend", Ticks: stopped(5)},
+		{Name: "acme/backend/bugfix", Ticks: stopped(10)},
+		{Name: "acme", Ticks: stopped(1)},
+		{Name: "other", Ticks: stopped(2)},
+		{
+			Name: "acme/backend/feature",
+			Ticks: &timer.Timer{Segments: []timer.Segment{segment("2025-03-11T20:00:00Z", "")}},
+		},
+	}
+
+	np := freeze(t, "2025-03-11T20:20:00Z")
+	nodes := timer.BuildTree(nts)
+
+	lines := make([]string, 0)
+	for _, node := range nodes {
+		node.Walk(func(n *timer.Node, depth int) {
+			lines = append(lines, fmt.Sprintf("%s%s=%s", strings.Repeat(" ", depth), n.Name, n.Elapsed(np)))
+		})
+	}
+
+	want := []string{
+		"acme=36m0s",
+		" backend=30m0s",
+		"  bugfix=10m0s",
+		"  feature=20m0s",
+		" frontend=5m0s",
+		"other=2m0s",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("BuildTree built the wrong tree:\n\nwanted\n%v\n\ngot\n%v", strings.Join(want, "\n"), strings.Join(lines, "\n"))
+	}
+
+	backend := timer.Find(nodes, "acme/backend")
+	if backend == nil {
+		t.Fatalf("Find didn't find a parent node")
+	}
+	if backend.Ticks != nil {
+		t.Errorf("A parent that was never used as a timer has ticks")
+	}
+	if !backend.IsRunning() {
+		t.Errorf("IsRunning didn't roll up a running descendant")
+	}
+	if timer.Find(nodes, "acme/missing") != nil {
+		t.Errorf("Find returned a node for a missing path")
+	}
+}